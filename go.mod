@@ -3,6 +3,7 @@ module github.com/sag-enhanced/native-app
 go 1.24
 
 require (
+	fyne.io/systray v1.12.2
 	github.com/denisbrodbeck/machineid v1.0.1
 	github.com/gen2brain/beeep v0.0.0-20240516210008-9c006672e7f4
 	github.com/go-jose/go-jose/v4 v4.0.5
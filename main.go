@@ -4,28 +4,19 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path"
 	"runtime"
 	"strings"
 
 	app "github.com/sag-enhanced/native-app/src"
+	"github.com/sag-enhanced/native-app/src/cli"
+	"github.com/sag-enhanced/native-app/src/config"
+	"github.com/sag-enhanced/native-app/src/deeplink"
 	"github.com/sag-enhanced/native-app/src/isadmin"
+	"github.com/sag-enhanced/native-app/src/logging"
 	"github.com/sag-enhanced/native-app/src/options"
 )
 
-var defaultProxyBypassList = []string{
-	// SAGE
-	"*.sage.party",
-	"*.leodev.cloud",
-	"*.sagemail.top",
-
-	// Brave browser
-	"*.brave.com",
-	"*.brave-http-only.com",
-
-	// Captcha solvers
-	"*.nopecha.com",
-}
-
 func main() {
 	if isadmin.IsAdmin() {
 		if runtime.GOOS == "windows" {
@@ -34,7 +25,8 @@ func main() {
 			fmt.Println("Running as administrator is not supported on Windows. Please run without admin privileges.")
 			os.Exit(1)
 		}
-		fmt.Println("Running as root is not recommended. SAGE does not need root privileges to function properly.")
+		// stderr, so this doesn't end up in the JSON-RPC stream of -ui stdio
+		fmt.Fprintln(os.Stderr, "Running as root is not recommended. SAGE does not need root privileges to function properly.")
 	}
 
 	opt := options.NewOptions()
@@ -43,38 +35,87 @@ func main() {
 	var releaseOverride int
 	var loopbackPort int
 	flag.StringVar(&opt.DataDirectory, "data", opt.DataDirectory, "Data directory to use")
-	flag.StringVar(&opt.Realm, "realm", options.StableRealm, "Run the app in the specified realm")
+	flag.StringVar(&opt.Realm, "realm", options.StableRealm, "Run the app in the specified realm (stable, beta, dev, local, a file in realms/ of the data directory or a path to a .json realm file)")
 	flag.BoolVar(&opt.Verbose, "verbose", false, "Enable VERY verbose logging")
+	flag.StringVar(&opt.LogLevels, "log", "", fmt.Sprintf("Log levels, either for everything or per component (eg. \"warn,browser=debug\"; components: %s)", strings.Join(logging.Components, ", ")))
 	flag.StringVar(&openCommand, "open", "", "Command to open URLs")
-	flag.StringVar(&opt.UI, "ui", opt.UI, "UI to use (webview or playwright)")
+	flag.StringVar(&opt.UI, "ui", opt.UI, "UI to use (webview, playwright, stdio or socket)")
+	flag.StringVar(&opt.UISocket, "socket", "", "Unix socket to listen on with -ui socket (default: sage.sock in the data directory)")
 	flag.BoolVar(&opt.SteamDev, "steamdev", false, "Enable Steam Dev mode")
 	flag.BoolVar(&opt.NoCompress, "nocompress", false, "Disable file compression")
+	flag.BoolVar(&opt.LockOnExit, "lockonexit", false, "Forget the encryption key as part of the shutdown")
 	flag.IntVar(&buildOverride, "build", -1, "Override/spoof build number (NOT RECOMMENDED)")
 	flag.IntVar(&releaseOverride, "release", -1, "Override/spoof release number (NOT RECOMMENDED)")
 	flag.IntVar(&loopbackPort, "loopback", -1, fmt.Sprintf("Port to use for loopback connections (default: %d) (NOT RECOMMENDED)", opt.LoopbackPort))
 	flag.StringVar(&opt.ForceBrowser, "forcebrowser", "", "Force a specific browser to be used (specify full executable path)")
-	flag.StringVar(&opt.ProxyBypassList, "proxybypasslist", strings.Join(defaultProxyBypassList, ";"), "Bypass any specified proxy for the given semi-colon-separated list of hosts")
+	flag.StringVar(&opt.ProxyBypassList, "proxybypasslist", "", "Bypass any specified proxy for the given semi-colon-separated list of hosts (default: the list of the realm, \"-\" for none)")
+	flag.BoolVar(&opt.NoRegister, "noregister", false, "Don't register the app as handler of sage:// links")
+	flag.BoolVar(&opt.CloseToTray, "closetotray", false, "Hide the window in the tray when it is closed, instead of quitting")
+	flag.BoolVar(&opt.PlaywrightHeadless, "playwrightheadless", false, "Run the browser of -ui playwright without a window")
+	flag.BoolVar(&opt.PlaywrightTrace, "playwrighttrace", false, "Record a playwright trace and HAR into the data directory (contains secrets)")
 	flag.Parse()
 
+	// the config file and the environment only fill in what wasn't passed as a flag
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	if dataDirectory, ok := os.LookupEnv(config.EnvName("data")); ok && !explicit["data"] {
+		opt.DataDirectory = dataDirectory
+	}
+	command := flag.Arg(0)
+	// a broken config or realm is one of the things the doctor looks at
+	if err := config.Load(opt, explicit); err != nil && command != "doctor" {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := opt.LoadRealm(); err != nil && command != "doctor" {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// the OS passes links as the last argument
+	if raw, ok := deeplink.Find(flag.Args()); ok {
+		link, err := deeplink.Parse(raw)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opt.DeepLink = link
+	}
+
+	if opt.UISocket == "" {
+		opt.UISocket = path.Join(opt.DataDirectory, "sage.sock")
+	}
 	if openCommand != "" {
 		opt.OpenCommand = strings.Split(openCommand, " ")
 	}
 	if buildOverride != -1 {
-		fmt.Println("WARNING: Build number override is not recommended and may cause issues.")
+		fmt.Fprintln(os.Stderr, "WARNING: Build number override is not recommended and may cause issues.")
 		opt.Build = uint32(buildOverride)
 	}
 	if releaseOverride != -1 {
-		fmt.Println("WARNING: Release number override is not recommended and may cause issues.")
+		fmt.Fprintln(os.Stderr, "WARNING: Release number override is not recommended and may cause issues.")
 		opt.Release = uint32(releaseOverride)
 	}
 	if loopbackPort != -1 {
 		opt.LoopbackPort = uint16(loopbackPort)
 	}
 	if opt.Realm != options.StableRealm {
-		fmt.Println("WARNING: Using experimental realm. This may cause issues.")
+		fmt.Fprintln(os.Stderr, "WARNING: Using experimental realm. This may cause issues.")
+	}
+
+	if cli.IsCommand(command) {
+		os.Exit(cli.Run(opt, flag.Args()))
+	}
+	if command != "" && opt.DeepLink == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", command)
+		os.Exit(2)
 	}
 
 	if err := app.Run(opt); err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
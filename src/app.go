@@ -1,25 +1,175 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/sag-enhanced/native-app/src/bindings"
+	"github.com/sag-enhanced/native-app/src/bundle"
+	"github.com/sag-enhanced/native-app/src/deeplink"
 	"github.com/sag-enhanced/native-app/src/file"
+	"github.com/sag-enhanced/native-app/src/instance"
+	"github.com/sag-enhanced/native-app/src/logging"
 	"github.com/sag-enhanced/native-app/src/options"
+	"github.com/sag-enhanced/native-app/src/tray"
 	"github.com/sag-enhanced/native-app/src/ui"
 )
 
-func Run(options *options.Options) error {
-	os.MkdirAll(options.DataDirectory, 0755)
+// how long the shutdown may take before we give up on whatever is still running
+const shutdownTimeout = 10 * time.Second
 
-	fm, err := file.NewFileManager(options)
+var logger = logging.For("app")
+
+func Run(opt *options.Options) error {
+	os.MkdirAll(opt.DataDirectory, 0755)
+	if err := logging.Setup(opt); err != nil {
+		return err
+	}
+
+	headless := opt.UI == options.StdioUI || opt.UI == options.SocketUI
+
+	inst, err := instance.Acquire(opt)
+	if errors.Is(err, instance.ErrAlreadyRunning) {
+		// scripted runs need their own data directory, there is no window to hand over to
+		if headless {
+			return err
+		}
+		if err := instance.Forward(opt, os.Args[1:]); err != nil {
+			return fmt.Errorf("%w, but it can't be reached: %w", instance.ErrAlreadyRunning, err)
+		}
+		logger.Info("Handed over to the running instance")
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	ui := ui.NewUI(options)
+	defer inst.Close()
+
+	if !headless && !opt.NoRegister {
+		registerScheme(opt)
+	}
+	if opt.DeepLink != nil && opt.DeepLink.Action != deeplink.ActionOpen {
+		// links to the realm are already where the page starts out, anything else is for the page to pick up
+		deeplink.SetPending(opt.DeepLink)
+	}
 
-	bindings := bindings.NewBindings(options, ui, fm)
+	// before anything looks at the realm origin, it may be replaced with the bundle server
+	server, err := bundle.Start(opt)
+	if err != nil {
+		return err
+	}
+	if server != nil {
+		defer server.Close()
+	}
+
+	fm, err := file.NewFileManager(opt)
+	if err != nil {
+		return err
+	}
+	// the trace and the HAR would keep in plain text what the encryption protects
+	if opt.UI == options.PlaywrightUI && opt.PlaywrightTrace && fm.Manifest != nil {
+		return errors.New("-playwrighttrace can't be used while the storage is encrypted; disable the encryption or use another data directory")
+	}
+	ui := ui.NewUI(opt)
+
+	bindings := bindings.NewBindings(opt, ui, fm)
 	ui.SetBindHandler(bindings.BindHandler)
+	ui.SetNavigationHandler(bindings.NavigationHandler)
+	ui.SetHangHandler(bindings.HangHandler)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		logger.Info("Received signal, shutting down", "signal", sig)
+		ui.Quit()
+		// a second signal means the user doesn't want to wait for the shutdown
+		<-signals
+		os.Exit(1)
+	}()
+
+	openLink := func(raw string) {
+		link, err := deeplink.Parse(raw)
+		if err != nil {
+			logger.Warn("Ignoring deep link", "error", err)
+			return
+		}
+		logger.Info("Opening deep link", "action", link.Action)
+		ui.Focus()
+		if link.Action == deeplink.ActionOpen {
+			ui.Navigate(link.Target(opt.GetRealmOrigin()))
+			return
+		}
+		deeplink.SetPending(link)
+		ui.Emit("sagel")
+	}
+	deeplink.Listen(openLink)
+
+	go inst.Serve(func(args []string) {
+		logger.Info("Launched again", "args", args)
+		ui.Focus()
+		ui.Emit("sagei", args)
+		if raw, ok := deeplink.Find(args); ok {
+			openLink(raw)
+		}
+	})
+
+	stopTray := startTray(ui, bindings)
 	ui.Run()
+	stopTray()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	bindings.Shutdown(ctx)
 	return nil
 }
+
+// the tray needs a native main loop to live on, so it only exists with the webview UI
+func startTray(u ui.UII, b *bindings.Bindings) func() {
+	loop, ok := u.(ui.NativeLoop)
+	if !ok {
+		return func() {}
+	}
+	// both run on the UI thread
+	stop := func() {}
+	loop.OnUIThread(func() {
+		stop = tray.Start(tray.Actions{
+			Show: u.Focus,
+			Lock: b.EncryptionLock,
+			CanLock: func() bool {
+				status := b.EncryptionStatus()
+				return status.Enabled && !status.Locked
+			},
+			Browsers: func() []string {
+				handles := []string{}
+				for _, h := range b.HandleList() {
+					if h.Kind == "browser" {
+						handles = append(handles, h.Handle)
+					}
+				}
+				return handles
+			},
+			KillBrowser: b.BrowserDestroy,
+			Quit:        u.Quit,
+			Emit:        u.Emit,
+		})
+	})
+	return func() { stop() }
+}
+
+// makes sage:// links open this executable (with this data directory)
+func registerScheme(opt *options.Options) {
+	executable, err := os.Executable()
+	if err != nil {
+		logger.Warn("Failed to register URL scheme", "error", err)
+		return
+	}
+	if err := deeplink.Register(executable, []string{"-data", opt.DataDirectory}); err != nil {
+		logger.Warn("Failed to register URL scheme", "error", err)
+	}
+}
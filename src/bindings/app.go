@@ -29,8 +29,8 @@ func (b *Bindings) Info() (map[string]any, error) {
 	}
 
 	url := ""
-	if currentUrl != nil {
-		url = currentUrl.String()
+	if current := currentUrl.Load(); current != nil {
+		url = current.String()
 	}
 
 	return map[string]any{
@@ -0,0 +1,175 @@
+package bindings
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/sag-enhanced/native-app/src/helper"
+)
+
+// every RPC call ends up in logs/audit.log (JSON lines) in the data directory;
+// params are written too, except for the ones listed in redactions
+
+const (
+	auditMaxSize    = 1024 * 1024
+	auditBackups    = 3
+	auditMaxEntries = 1000
+)
+
+const (
+	auditResolved = "resolved"
+	auditRejected = "rejected"
+	// the call never made it to the binding (unknown method, bad arguments)
+	auditFailed = "failed"
+	auditDenied = "denied"
+	// the page navigated away before the call finished
+	auditDropped = "dropped"
+)
+
+// navigations out of the realm are logged as calls of this method, with the outcome the UI
+// reports (ui.NavigationBlocked or ui.NavigationOpened)
+const auditNavigation = "navigate"
+
+var redactedValue = json.RawMessage(`"[redacted]"`)
+
+// params that must never be written anywhere: passwords, keys, secrets and bodies
+var redactions = map[string][]string{
+	"SetUrl":           {"secret"},
+	"EncryptionEnable": {"passwords"},
+	"EncryptionUnlock": {"password"},
+	"InitConnect":      {"handover"},
+	"ApproveConnect":   {"secret", "password"},
+	"RecoverConnect":   {"secret", "data", "password"},
+
+	"Set":   {"value"},
+	"Sign2": {"message"},
+
+	"Seal":                  {"data"},
+	"Unseal":                {"data"},
+	"SealBlob":              {"data"},
+	"UnsealBlob":            {"data"},
+	"SealWithKey":           {"data", "key"},
+	"UnsealWithKey":         {"data", "key"},
+	"SealWithKeyBlob":       {"data", "key"},
+	"UnsealWithKeyBlob":     {"data", "key"},
+	"SealWithPublicKey":     {"data"},
+	"SealWithPublicKeyBlob": {"data"},
+
+	// proxy URLs may contain credentials
+	"HttpClient": {"proxyUrl"},
+	"ProxyNew":   {"proxyUrl"},
+	"BrowserNew": {"proxy"},
+
+	"HttpRequest":     {"headers", "body"},
+	"HttpRequestBlob": {"headers", "body"},
+	"HttpFetch":       {"request"},
+	"HttpCookie":      {"value"},
+	"ServerRespond":   {"headers", "body"},
+
+	"FsWriteFile":    {"content"},
+	"FsWriteBlob":    {"data"},
+	"Save":           {"data"},
+	"Save2":          {"data"},
+	"SaveBlob":       {"data"},
+	"SteamPatch":     {"js"},
+	"ExtSetManifest": {"manifest"},
+}
+
+type AuditEntry struct {
+	Time     time.Time       `json:"time"`
+	Method   string          `json:"method"`
+	Origin   string          `json:"origin"`
+	CallId   int             `json:"callId"`
+	Params   json.RawMessage `json:"params,omitempty"`
+	Duration int64           `json:"durationMs"`
+	Outcome  string          `json:"outcome"`
+	Code     string          `json:"code,omitempty"`
+}
+
+type auditLog struct {
+	file *helper.RotatingFile
+}
+
+func newAuditLog(dataDirectory string) *auditLog {
+	return &auditLog{
+		file: helper.NewRotatingFile(path.Join(dataDirectory, "logs", "audit.log"), auditMaxSize, auditBackups),
+	}
+}
+
+func (a *auditLog) record(entry *AuditEntry, outcome string, err error) {
+	entry.Duration = time.Since(entry.Time).Milliseconds()
+	entry.Outcome = outcome
+	if err != nil {
+		entry.Code = errorCode(err)
+	}
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if _, err := a.file.Write(append(encoded, '\n')); err != nil {
+		logger.Warn("Failed to write audit log", "error", err)
+	}
+}
+
+// called by the UI for every navigation out of the realm it stopped; query and fragment
+// of the URL are left out, they may contain tokens
+func (b *Bindings) NavigationHandler(window string, rawUrl string, outcome string) {
+	target := "[redacted]"
+	if u, err := url.Parse(rawUrl); err == nil {
+		target = (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path, Opaque: u.Opaque}).String()
+	}
+	params, err := json.Marshal([]string{window, target})
+	if err != nil {
+		return
+	}
+	entry := &AuditEntry{Time: time.Now(), Method: auditNavigation, Origin: b.callerOrigin(""), CallId: -1, Params: params}
+	b.audit.record(entry, outcome, nil)
+}
+
+// unknown methods and extra arguments are redacted as well, since we can't know what they contain
+func redactParams(methodName string, params string) json.RawMessage {
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(params), &raw); err != nil {
+		return nil
+	}
+	binding, ok := dispatch[methodName]
+	redacted := make([]json.RawMessage, len(raw))
+	for i, value := range raw {
+		redacted[i] = redactedValue
+		if ok && i < len(binding.manifest.Params) && !slices.Contains(redactions[methodName], binding.manifest.Params[i].Name) {
+			redacted[i] = value
+		}
+	}
+	encoded, err := json.Marshal(redacted)
+	if err != nil {
+		return nil
+	}
+	return encoded
+}
+
+// the most recent entries of the audit log, oldest first
+func (b *Bindings) AuditLog(limit int) ([]AuditEntry, error) {
+	if limit <= 0 || limit > auditMaxEntries {
+		limit = auditMaxEntries
+	}
+	data, err := b.audit.file.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	entries := []AuditEntry{}
+	for i := len(lines) - 1; i >= 0 && len(entries) < limit; i-- {
+		var entry AuditEntry
+		// skips empty and partially written lines
+		if json.Unmarshal(lines[i], &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
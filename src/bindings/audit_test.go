@@ -0,0 +1,47 @@
+package bindings
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRedactParams(t *testing.T) {
+	// params of bindings that aren't listed are kept
+	if redacted := string(redactParams("BlobFree", `["abc"]`)); redacted != `["abc"]` {
+		t.Errorf("BlobFree: %s", redacted)
+	}
+	// only the listed ones are replaced, by name
+	if redacted := string(redactParams("SetUrl", `["https://app.sage.party/","s3cret"]`)); redacted != `["https://app.sage.party/","[redacted]"]` {
+		t.Errorf("SetUrl: %s", redacted)
+	}
+	if redacted := string(redactParams("SealWithKey", `["data","key"]`)); redacted != `["[redacted]","[redacted]"]` {
+		t.Errorf("SealWithKey: %s", redacted)
+	}
+	// we can't know what unknown methods and extra arguments carry
+	if redacted := string(redactParams("NoSuchMethod", `["password",1]`)); redacted != `["[redacted]","[redacted]"]` {
+		t.Errorf("unknown method: %s", redacted)
+	}
+	if redacted := string(redactParams("BlobFree", `["abc","password"]`)); redacted != `["abc","[redacted]"]` {
+		t.Errorf("extra argument: %s", redacted)
+	}
+	// and params that aren't an array aren't written at all
+	if redacted := redactParams("EncryptionUnlock", `{"password":"hunter2"}`); redacted != nil {
+		t.Errorf("object params: %s", redacted)
+	}
+}
+
+// a name that doesn't match the binding would quietly write the secret to the log
+func TestRedactionsMatchBindings(t *testing.T) {
+	for method, names := range redactions {
+		binding, ok := dispatch[method]
+		if !ok {
+			t.Errorf("%s is not a binding", method)
+			continue
+		}
+		for _, name := range names {
+			if !slices.ContainsFunc(binding.manifest.Params, func(param ParamManifest) bool { return param.Name == name }) {
+				t.Errorf("%s has no param %s", method, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Code generated by gen/main.go; DO NOT EDIT.
+
+export interface AuditEntry {
+  time: any;
+  method: string;
+  origin: string;
+  callId: number;
+  params?: any;
+  durationMs: number;
+  outcome: string;
+  code?: string;
+}
+
+export interface BlobEndpoint {
+  url: string;
+  token: string;
+}
+
+export interface ConfigSetting {
+  key: string;
+  value: any;
+  source: string;
+  saved: any;
+  editable: boolean;
+}
+
+export interface Data {
+  text?: string | null;
+  base64?: string | null;
+  blob?: string | null;
+}
+
+export interface Blob {
+  blob: string;
+  size: number;
+}
+
+export interface EncryptionStatus {
+  enabled: boolean;
+  locked: boolean;
+}
+
+export interface DeepLink {
+  action: string;
+  path: string;
+  params: Record<string, string>;
+}
+
+export interface DiagnosticsCheck {
+  name: string;
+  status: string;
+  detail: string;
+  hint?: string;
+}
+
+export interface DiagnosticsReport {
+  time: any;
+  build: number;
+  release: number;
+  os: string;
+  arch: string;
+  ui: string;
+  realm: string;
+  checks: DiagnosticsCheck[];
+}
+
+export interface ParamManifest {
+  name: string;
+  type: string;
+}
+
+export interface MethodManifest {
+  name: string;
+  params: ParamManifest[];
+  returns: string;
+  cancellable: boolean;
+}
+
+export interface BindingsManifest {
+  build: number;
+  release: number;
+  methods: MethodManifest[];
+}
+
+export interface HandleInfo {
+  handle: string;
+  kind: string;
+  origin: string;
+  created: any;
+  lastUsed: any;
+}
+
+export interface HTTPFetchRequest {
+  method?: string;
+  url: string;
+  headers?: Record<string, string[]>;
+  body?: Data | null;
+  redirects?: number | null;
+}
+
+export interface HTTPRedirect {
+  status: number;
+  url: string;
+  headers: Record<string, string[]>;
+  location: string;
+}
+
+export interface HTTPFetchResponse {
+  status: number;
+  url: string;
+  headers: Record<string, string[]>;
+  trailers: Record<string, string[]>;
+  redirects: HTTPRedirect[];
+  body: Blob | null;
+}
+
+export interface HTTPResponse {
+  status: number;
+  headers: Record<string, string>;
+  body: string;
+}
+
+export interface HTTPBlobResponse {
+  status: number;
+  headers: Record<string, string>;
+  body: Blob | null;
+}
+
+export interface OfflineStatus {
+  offline: boolean;
+  reason?: string;
+  version?: string;
+}
+
+export interface WindowOptions {
+  title: string;
+  width: number;
+  height: number;
+}
+
+export interface SageBindings {
+  alert(message: string): Promise<void>;
+  approveConnect(secret: string, approveIntent: string, password: string): Promise<string>;
+  auditLog(limit: number): Promise<AuditEntry[]>;
+  blobEndpoint(): Promise<BlobEndpoint>;
+  blobFree(handle: string): Promise<void>;
+  browserDestroy(handle: string): Promise<void>;
+  browserDestroyProfile(browser: string, profileId: number): Promise<void>;
+  browserNew(pageUrl: string, browser: string, proxy: string | null, profileId: number): Promise<string>;
+  build(): Promise<number>;
+  cancel(callId: number): Promise<void>;
+  configGet(): Promise<ConfigSetting[]>;
+  configSet(key: string, value: any): Promise<void>;
+  deepLink(): Promise<DeepLink | null>;
+  describe(): Promise<BindingsManifest>;
+  diagnostics(): Promise<DiagnosticsReport | null>;
+  encryptionDisable(): Promise<void>;
+  encryptionEnable(passwords: string[]): Promise<void>;
+  encryptionLock(): Promise<void>;
+  encryptionStatus(): Promise<EncryptionStatus>;
+  encryptionUnlock(password: string): Promise<void>;
+  ext(browser: string): Promise<Record<string, string> | null>;
+  extGetManifest(name: string, browser: string): Promise<string>;
+  extInstall(name: string, browser: string, download: string): Promise<void>;
+  extSetManifest(name: string, browser: string, manifest: string): Promise<void>;
+  extUninstall(name: string, browser: string): Promise<void>;
+  fsDeleteFile(filename: string): Promise<void>;
+  fsListFiles(dirname: string): Promise<string[]>;
+  fsMkdir(dirname: string): Promise<void>;
+  fsReadBlob(filename: string): Promise<Blob | null>;
+  fsReadFile(filename: string): Promise<string>;
+  fsWriteBlob(filename: string, data: Data): Promise<void>;
+  fsWriteFile(filename: string, content: string): Promise<void>;
+  get(key: string): Promise<string>;
+  handleDestroyAll(): Promise<number>;
+  handleList(): Promise<HandleInfo[]>;
+  httpClient(proxyUrl: string | null): Promise<string>;
+  httpCookie(handle: string, domain: string, name: string, value: string | null): Promise<string>;
+  httpDestroy(handle: string): Promise<void>;
+  httpFetch(handle: string, request: HTTPFetchRequest): Promise<HTTPFetchResponse | null>;
+  httpRequest(handle: string, method: string, url: string, headers: Record<string, string>, body: string): Promise<HTTPResponse | null>;
+  httpRequestBlob(handle: string, method: string, url: string, headers: Record<string, string>, body: Data): Promise<HTTPBlobResponse | null>;
+  httpSetRedirects(handle: string, redirects: number): Promise<void>;
+  id(): Promise<string>;
+  info(): Promise<Record<string, any>>;
+  initConnect(handover: string, resource: string): Promise<void>;
+  notify(title: string, message: string, alert: boolean): Promise<void>;
+  offline(): Promise<OfflineStatus>;
+  open(target: string): Promise<void>;
+  proxyDestroy(handle: string): Promise<void>;
+  proxyNew(proxyUrl: string): Promise<string>;
+  quit(): Promise<void>;
+  read(filterText: string, filter: string): Promise<string>;
+  recoverConnect(secret: string, data: string, password: string): Promise<void>;
+  save(filename: string, data: string): Promise<void>;
+  save2(filename: string, data: string): Promise<void>;
+  saveBlob(filename: string, data: Data): Promise<void>;
+  screenshotQR(): Promise<string[]>;
+  seal(data: string): Promise<string>;
+  sealBlob(data: Data): Promise<Blob | null>;
+  sealWithKey(data: string, key: string): Promise<string>;
+  sealWithKeyBlob(data: Data, key: string): Promise<Blob | null>;
+  sealWithPublicKey(data: string, publicKey: string): Promise<string>;
+  sealWithPublicKeyBlob(data: Data, publicKey: string): Promise<Blob | null>;
+  serverDestroy(): Promise<void>;
+  serverNew(): Promise<string>;
+  serverRespond(requestId: number, statusCode: number, headers: Record<string, string>, body: string): Promise<void>;
+  set(key: string, value: string): Promise<void>;
+  setUrl(currentPageUrl: string, secret: string): Promise<void>;
+  sign2(message: string): Promise<string>;
+  start(): Promise<number>;
+  steamPatch(js: string): Promise<void>;
+  steamRun(): Promise<void>;
+  unseal(data: string): Promise<string>;
+  unsealBlob(data: Data): Promise<Blob | null>;
+  unsealWithKey(data: string, key: string): Promise<string>;
+  unsealWithKeyBlob(data: Data, key: string): Promise<Blob | null>;
+  windowClose(name: string): Promise<void>;
+  windowList(): Promise<string[]>;
+  windowMinimize(name: string): Promise<void>;
+  windowOpen(name: string, path: string, options: WindowOptions): Promise<void>;
+  windowRestore(name: string): Promise<void>;
+  windowSetAlwaysOnTop(name: string, enabled: boolean): Promise<void>;
+  windowSetSize(name: string, width: number, height: number): Promise<void>;
+  windowSetTitle(name: string, title: string): Promise<void>;
+}
@@ -1,96 +1,250 @@
 package bindings
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"reflect"
+	"runtime/debug"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sag-enhanced/native-app/src/file"
+	"github.com/sag-enhanced/native-app/src/helper"
+	"github.com/sag-enhanced/native-app/src/logging"
 	"github.com/sag-enhanced/native-app/src/options"
 	"github.com/sag-enhanced/native-app/src/ui"
 )
 
+var logger = logging.For("bindings")
+
 type Bindings struct {
 	options *options.Options
 	ui      ui.UII
 	fm      *file.FileManager
+	audit   *auditLog
+	// no new calls are accepted once this is set
+	closing atomic.Bool
 }
 
 func NewBindings(options *options.Options, ui ui.UII, fm *file.FileManager) *Bindings {
-	return &Bindings{options, ui, fm}
+	go runHandleGC()
+	return &Bindings{options: options, ui: ui, fm: fm, audit: newAuditLog(options.DataDirectory)}
 }
 
+//go:generate go run ./gen
+
 // our own RPC engine ontop of the one that webview already provides
 // because the webview one is blocking and we want to be able to call
 // functions that take a while to complete (eg make a network request)
 
-func (b *Bindings) BindHandler(method string, callId int, params string) error {
-	if b.options.Verbose {
-		fmt.Println("RPC call:", method, callId, params)
+func (b *Bindings) BindHandler(origin string, method string, callId int, params string) error {
+	origin = b.callerOrigin(origin)
+	methodName := method
+	if method != "" {
+		methodName = strings.ToUpper(method[:1]) + method[1:]
 	}
-	prefix := fmt.Sprintf("if(saged[%d]){", callId)
-	suffix := fmt.Sprintf(";delete saged[%d]}", callId)
+	entry := &AuditEntry{Time: time.Now(), Method: method, Origin: origin, CallId: callId, Params: redactParams(methodName, params)}
+	logger.Debug("RPC call", "origin", origin, "method", method, "callId", callId, "params", entry.Params)
 
-	methodName := strings.ToUpper(method[:1]) + method[1:]
+	if b.closing.Load() {
+		b.audit.record(entry, auditFailed, errShuttingDown)
+		return errShuttingDown
+	}
 
-	bindingType := reflect.TypeOf(b)
-	binding, ok := bindingType.MethodByName(methodName)
+	binding, ok := dispatch[methodName]
 	if !ok {
-		return fmt.Errorf("method not found: %s (%s)", method, methodName)
+		err := withMessageAndDetails(ErrNotFound, fmt.Sprintf("method not found: %s", method), map[string]string{"method": method})
+		b.audit.record(entry, auditFailed, err)
+		return err
+	}
+
+	if err := b.checkPolicy(methodName, origin); err != nil {
+		logger.Warn("Denied RPC call", "origin", origin, "method", method, "callId", callId, "error", err)
+		b.audit.record(entry, auditDenied, err)
+		b.ui.Reject(callId, encodeError(err))
+		return nil
 	}
 
 	var raw []json.RawMessage
 	if err := json.Unmarshal([]byte(params), &raw); err != nil {
+		b.audit.record(entry, auditFailed, err)
 		return err
 	}
-	if len(raw)+1 < binding.Type.NumIn() {
-		return fmt.Errorf("wrong number of arguments (got %d, expected %d)", len(raw), binding.Type.NumIn())
+	if len(raw) < len(binding.manifest.Params) {
+		err := fmt.Errorf("wrong number of arguments (got %d, expected %d)", len(raw), len(binding.manifest.Params))
+		b.audit.record(entry, auditFailed, err)
+		return err
 	}
 
-	args := []reflect.Value{reflect.ValueOf(b)}
-	for i := range raw {
-		arg := reflect.New(binding.Type.In(1 + i))
-		if err := json.Unmarshal(raw[i], arg.Interface()); err != nil {
-			return err
+	ctx, c := newCall(callId, methodName, origin)
+	ctx = helper.WithProgress(ctx, func(progress helper.Progress) {
+		if ctx.Err() != nil {
+			return
 		}
-		args = append(args, arg.Elem())
-	}
+		encoded, err := json.Marshal(progress)
+		if err == nil {
+			b.ui.Progress(callId, encoded)
+		}
+	})
 
-	go func() {
-		result, err := parseResults(binding.Func.Call(args))
+	run := func() {
+		result, err := b.callBinding(ctx, methodName, binding, raw)
+		c.done()
+		// the page that made the call is gone; its call ids belong to the new page now
+		if errors.Is(context.Cause(ctx), errNavigated) {
+			logger.Debug("Dropping result of RPC call after navigation", "method", method, "callId", callId)
+			b.audit.record(entry, auditDropped, err)
+			if dropped, ok := b.ui.(ui.DroppedCalls); ok {
+				dropped.Dropped(callId, encodeError(errNavigated))
+			}
+			return
+		}
 
 		if err != nil {
-			b.ui.Eval(prefix + fmt.Sprintf("saged[%d].b(new Error(%q))", callId, err.Error()) + suffix)
+			b.audit.record(entry, auditRejected, err)
+			b.ui.Reject(callId, encodeError(err))
 			return
 		}
 		encoded, err := json.Marshal(result)
 		if err != nil {
-			fmt.Println("Failed to marshal result of RPC function", method, err)
-			b.ui.Eval(prefix + fmt.Sprintf("saged[%d].b(new Error('result marshal failed'));", callId) + suffix)
+			logger.Error("Failed to marshal result of RPC function", "method", method, "error", err)
+			err = errors.New("result marshal failed")
+			b.audit.record(entry, auditRejected, err)
+			b.ui.Reject(callId, encodeError(err))
 			return
 		}
-		b.ui.Eval(prefix + fmt.Sprintf("saged[%d].a(%s)", callId, string(encoded)) + suffix)
-	}()
+		b.audit.record(entry, auditResolved, nil)
+		b.ui.Resolve(callId, encoded)
+	}
+
+	// the URL has to be known before the calls following it are checked
+	if methodName == "SetUrl" {
+		run()
+	} else {
+		go run()
+	}
 	return nil
 }
 
-func parseResults(results []reflect.Value) (interface{}, error) {
-	if len(results) == 0 {
-		return nil, nil
-	}
-	if len(results) == 1 {
-		if err, ok := results[0].Interface().(error); ok {
-			return nil, err
+func (b *Bindings) callBinding(ctx context.Context, methodName string, binding *binding, raw []json.RawMessage) (result any, err error) {
+	// a bug in one binding shouldn't take down the whole app
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Panic in RPC function", "method", methodName, "panic", r, "stack", string(debug.Stack()))
+			err = withMessage(errInternal, fmt.Sprintf("internal error: %v", r))
 		}
-		return results[0].Interface(), nil
+	}()
+	return binding.call(b, ctx, raw)
+}
+
+var errNavigated = errors.New("page navigated away")
+
+var calls = map[int]*call{}
+
+// calls that haven't returned yet, including the cancelled ones
+var runningCalls = map[*call]bool{}
+var callSequence uint64
+var callLock = sync.Mutex{}
+
+type call struct {
+	id       int
+	method   string
+	sequence uint64
+	cancel   context.CancelCauseFunc
+	caller   *Caller
+}
+
+type callKey struct{}
+
+// who made a call; bindings that create handles take it as their first parameter, which
+// the dispatcher fills in (it isn't passed by the frontend, and doesn't make the call
+// cancellable)
+type Caller struct {
+	origin string
+	owner  owner
+}
+
+func newCall(callId int, method string, origin string) (context.Context, *call) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	caller := &Caller{origin: origin, owner: ownerPage}
+	if callId >= ui.WindowCallIds {
+		caller.owner = ownerWindow
+	}
+
+	callLock.Lock()
+	defer callLock.Unlock()
+	callSequence++
+	c := &call{id: callId, method: method, sequence: callSequence, cancel: cancel, caller: caller}
+	calls[callId] = c
+	runningCalls[c] = true
+	return context.WithValue(ctx, callKey{}, c), c
+}
+
+func (c *call) done() {
+	callLock.Lock()
+	// call ids restart with every page, so the slot may already belong to someone else
+	if calls[c.id] == c {
+		delete(calls, c.id)
 	}
-	if len(results) == 2 {
-		if err, ok := results[1].Interface().(error); ok {
-			return nil, err
+	delete(runningCalls, c)
+	callLock.Unlock()
+	c.cancel(context.Canceled)
+}
+
+// cancels every call that was made before the given one
+func cancelCallsBefore(ctx context.Context, cause error) {
+	current, _ := ctx.Value(callKey{}).(*call)
+
+	callLock.Lock()
+	defer callLock.Unlock()
+	for id, c := range calls {
+		if current != nil && c.sequence >= current.sequence {
+			continue
+		}
+		// the other windows have pages of their own, only shutting down ends their calls
+		if cause == errNavigated && id >= ui.WindowCallIds {
+			continue
 		}
-		return results[0].Interface(), nil
+		delete(calls, id)
+		c.cancel(cause)
+	}
+}
+
+// outside of a call (eg. in tests) everything belongs to the main page
+func callerOf(ctx context.Context) *Caller {
+	if current, _ := ctx.Value(callKey{}).(*call); current != nil {
+		return current.caller
+	}
+	return &Caller{owner: ownerPage}
+}
+
+// called by the UI when the page stops answering; whatever is waiting on it is logged
+func (b *Bindings) HangHandler(window string, since time.Duration) {
+	logger.Error("Pending while the page is not responding", "window", window, "calls", pendingCalls(), "loopback", pendingServerRequests())
+}
+
+// "<call id>:<method>" of the calls that haven't finished yet
+func pendingCalls() []string {
+	callLock.Lock()
+	defer callLock.Unlock()
+	pending := []string{}
+	for id, c := range calls {
+		pending = append(pending, fmt.Sprintf("%d:%s", id, c.method))
+	}
+	sort.Strings(pending)
+	return pending
+}
+
+func (b *Bindings) Cancel(callId int) {
+	callLock.Lock()
+	c, ok := calls[callId]
+	callLock.Unlock()
+	if ok {
+		logger.Debug("Cancelling RPC call", "callId", callId)
+		c.cancel(context.Canceled)
 	}
-	return nil, errors.New("too many return values")
 }
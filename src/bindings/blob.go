@@ -0,0 +1,149 @@
+package bindings
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// binary data doesn't have to cross the bridge as base64 anymore: bindings hand out
+// opaque blob handles and the frontend moves the actual bytes over the loopback
+// server, authenticated with a token that only lives as long as this process. every owner
+// gets a token of its own, so an upload belongs to whoever was handed the token
+
+const blobPath = "/.sage/blob"
+
+var blobTokens = map[owner]string{}
+
+func init() {
+	for _, owner := range []owner{ownerPage, ownerWindow} {
+		token := make([]byte, 32)
+		rand.Read(token)
+		blobTokens[owner] = base64.RawURLEncoding.EncodeToString(token)
+	}
+}
+
+type Blob struct {
+	Handle string `json:"blob"`
+	Size   int    `json:"size"`
+}
+
+// binary input of a binding; exactly one of the fields has to be set,
+// so there is no guessing whether a string is text or base64 anymore
+type Data struct {
+	Text   *string `json:"text,omitempty"`
+	Base64 *string `json:"base64,omitempty"`
+	Blob   *string `json:"blob,omitempty"`
+}
+
+func (d Data) Bytes() ([]byte, error) {
+	switch {
+	case d.Text != nil:
+		return []byte(*d.Text), nil
+	case d.Base64 != nil:
+		decoded, err := base64.StdEncoding.DecodeString(*d.Base64)
+		if err != nil {
+			return nil, withMessage(ErrInvalidArgument, "invalid base64 data")
+		}
+		return decoded, nil
+	case d.Blob != nil:
+		return getBlob(*d.Blob)
+	}
+	return nil, withMessage(ErrInvalidArgument, "data must be text, base64 or a blob")
+}
+
+func (b *Bindings) newBlob(caller *Caller, data []byte) (*Blob, error) {
+	handle, err := newHandleId()
+	if err != nil {
+		return nil, err
+	}
+	b.addHandle(caller, handleBlob, handle, data, nil)
+	return &Blob{Handle: handle, Size: len(data)}, nil
+}
+
+func getBlob(handle string) ([]byte, error) {
+	return getHandle[[]byte](handleBlob, handle)
+}
+
+type BlobEndpoint struct {
+	Url   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// GET {url}/{handle} downloads a blob, POST {url} uploads one;
+// both need the token in the X-Sage-Token header or the token query parameter
+func (b *Bindings) BlobEndpoint(caller *Caller) (BlobEndpoint, error) {
+	addr, err := b.ServerNew(caller)
+	if err != nil {
+		return BlobEndpoint{}, err
+	}
+	return BlobEndpoint{
+		Url:   "http://" + addr + blobPath,
+		Token: blobTokens[caller.owner],
+	}, nil
+}
+
+// the owner the token was handed to
+func blobOwner(token string) (owner, bool) {
+	for owner, expected := range blobTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			return owner, true
+		}
+	}
+	return 0, false
+}
+
+func (b *Bindings) BlobFree(handle string) {
+	destroyHandle(handleBlob, handle)
+}
+
+func (b *Bindings) serveBlob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", b.options.GetRealmOrigin())
+	w.Header().Set("Access-Control-Allow-Headers", "X-Sage-Token, Content-Type")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	token := r.Header.Get("X-Sage-Token")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	owner, ok := blobOwner(token)
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	handle := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, blobPath), "/")
+	switch {
+	case r.Method == http.MethodGet && handle != "":
+		data, err := getBlob(handle)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(data)
+	case r.Method == http.MethodPost && handle == "":
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		blob, err := b.newBlob(&Caller{origin: r.Header.Get("Origin"), owner: owner}, data)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(blob)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -3,8 +3,8 @@ package bindings
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +12,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/sag-enhanced/native-app/src/helper"
 	"github.com/sag-enhanced/native-app/src/options"
 )
 
@@ -44,20 +45,20 @@ func (b *Bindings) Ext(browser string) (*map[string]string, error) {
 	return &extensions, nil
 }
 
-func (b *Bindings) ExtInstall(name string, browser string, download string) error {
+func (b *Bindings) ExtInstall(ctx context.Context, name string, browser string, download string) error {
 	if !strings.HasPrefix(download, "https://github.com/") || strings.Contains(download, "..") {
-		return errors.New("invalid download URL")
+		return withMessage(ErrInvalidArgument, "invalid download URL")
 	}
 	if path.Clean(name) != name || strings.Contains(name, ",") {
-		return errors.New("invalid extension name")
+		return withMessage(ErrInvalidArgument, "invalid extension name")
 	}
 
-	return installExtensionFromGithub(name, browser, download, b.options)
+	return installExtensionFromGithub(ctx, name, browser, download, b.options)
 }
 
 func (b *Bindings) ExtGetManifest(name string, browser string) (string, error) {
 	if path.Clean(name) != name || strings.Contains(name, ",") {
-		return "", errors.New("invalid extension name")
+		return "", withMessage(ErrInvalidArgument, "invalid extension name")
 	}
 
 	manifest := path.Join(b.options.DataDirectory, "ext", browser, name, "manifest.json")
@@ -71,7 +72,7 @@ func (b *Bindings) ExtGetManifest(name string, browser string) (string, error) {
 
 func (b *Bindings) ExtSetManifest(name string, browser string, manifest string) error {
 	if path.Clean(name) != name || strings.Contains(name, ",") {
-		return errors.New("invalid extension name")
+		return withMessage(ErrInvalidArgument, "invalid extension name")
 	}
 
 	manifestPath := path.Join(b.options.DataDirectory, "ext", browser, name, "manifest.json")
@@ -80,7 +81,7 @@ func (b *Bindings) ExtSetManifest(name string, browser string, manifest string)
 
 func (b *Bindings) ExtUninstall(name string, browser string) error {
 	if path.Clean(name) != name || strings.Contains(name, ",") {
-		return errors.New("invalid extension name")
+		return withMessage(ErrInvalidArgument, "invalid extension name")
 	}
 
 	dir := path.Join(b.options.DataDirectory, "ext", browser, name)
@@ -91,8 +92,12 @@ type Manifest struct {
 	Version string `json:"version"`
 }
 
-func installExtensionFromGithub(name string, browser string, download string, options *options.Options) error {
-	resp, err := http.Get(download)
+func installExtensionFromGithub(ctx context.Context, name string, browser string, download string, options *options.Options) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", download, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -105,7 +110,7 @@ func installExtensionFromGithub(name string, browser string, download string, op
 
 	os.MkdirAll(dir, 0755)
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(helper.NewProgressReader(ctx, resp.Body, "download", resp.ContentLength))
 	if err != nil {
 		return err
 	}
@@ -115,7 +120,11 @@ func installExtensionFromGithub(name string, browser string, download string, op
 		return err
 	}
 
-	for _, file := range reader.File {
+	for i, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		helper.ReportProgress(ctx, helper.Progress{Phase: "extract", Bytes: int64(i + 1), Total: int64(len(reader.File))})
 		if file.FileInfo().IsDir() {
 			os.MkdirAll(path.Join(dir, file.Name), 0755)
 		} else {
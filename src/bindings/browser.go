@@ -2,90 +2,73 @@ package bindings
 
 import (
 	"context"
-	"crypto/rand"
-	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"path"
 	"strings"
-	"sync"
 
 	browserAPI "github.com/sag-enhanced/native-app/src/browser"
 )
 
-var browserHandles = map[string]context.CancelFunc{}
-var browserHandleLock = sync.Mutex{}
-
-func (b *Bindings) BrowserNew(pageUrl string, browser string, proxy *string, profileId int32) (string, error) {
-	rawHandle := make([]byte, 16)
-	var err error
-	if _, err := rand.Read(rawHandle); err != nil {
+func (b *Bindings) BrowserNew(ctx context.Context, pageUrl string, browser string, proxy *string, profileId int32) (string, error) {
+	handle, err := newHandleId()
+	if err != nil {
 		return "", err
 	}
-	handle := fmt.Sprintf("%x", rawHandle)
-	if b.options.Verbose {
-		fmt.Println("Created new browser instance with handle", handle)
-	}
+	logger.Debug("Created new browser instance", "handle", handle)
 	var parsedProxy *url.URL
+	uses := []string{}
 	if proxy != nil {
 		parsedProxy, err = url.Parse(*proxy)
 		if err != nil {
 			return "", err
 		}
 		if parsedProxy.Hostname() != "127.0.0.1" {
-			return "", errors.New("Only local proxies are allowed.")
+			return "", ErrLocalProxyOnly
 		}
+		uses = append(uses, *proxy)
 	}
 
 	if _, err := url.Parse(pageUrl); err != nil {
 		return "", err
 	}
 
+	proc, err := browserAPI.LaunchBrowser(ctx, b.options, pageUrl, browser, parsedProxy, profileId)
+	if err != nil {
+		return "", err
+	}
+
 	cancelCtx, cancel := context.WithCancel(context.Background())
+	exited := make(chan struct{})
+	b.addHandle(callerOf(ctx), handleBrowser, handle, proc, func(ctx context.Context) {
+		cancel()
+		select {
+		case <-exited:
+		case <-ctx.Done():
+		}
+	}, uses...)
 
 	go func() {
-		defer cancel()
-		defer func() {
-			browserHandleLock.Lock()
-			defer browserHandleLock.Unlock()
-			delete(browserHandles, handle)
-
-			if b.options.Verbose {
-				fmt.Println("Destroying browser instance with handle", handle)
-			}
-
-			b.ui.Eval(fmt.Sprintf("sagebd(%q)", handle))
-		}()
-		err := browserAPI.RunBrowser(cancelCtx, b.options, pageUrl, browser, parsedProxy, profileId)
-		if err != nil {
-			fmt.Println("Error running browser:", err)
+		browserAPI.WaitBrowser(cancelCtx, proc)
+		close(exited)
+		// the browser may have been closed by the user
+		destroyHandle(handleBrowser, handle)
+		if !b.closing.Load() {
+			b.ui.Emit("sagebd", handle)
 		}
 	}()
 
-	browserHandleLock.Lock()
-	browserHandles[handle] = cancel
-	browserHandleLock.Unlock()
 	return handle, nil
 }
 
 func (b *Bindings) BrowserDestroy(handle string) {
-	browserHandleLock.Lock()
-	defer browserHandleLock.Unlock()
-	cancelCtx, ok := browserHandles[handle]
-	if !ok {
-		return
-	}
-	delete(browserHandles, handle)
-	if b.options.Verbose {
-		fmt.Println("Destroying browser instance with handle", handle)
-	}
-	cancelCtx()
+	destroyHandle(handleBrowser, handle)
 }
 
 func (b *Bindings) BrowserDestroyProfile(browser string, profileId int32) error {
 	if strings.ContainsAny(browser+fmt.Sprint(profileId), "/\\.;:") {
-		return errors.New("invalid browser name")
+		return withMessage(ErrInvalidArgument, "invalid browser name")
 	}
 	profilePath := path.Join(b.options.DataDirectory, "profiles", browser)
 	if profileId > 0 {
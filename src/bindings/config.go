@@ -0,0 +1,43 @@
+package bindings
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/sag-enhanced/native-app/src/config"
+)
+
+type ConfigSetting struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Source   string `json:"source"`
+	Saved    any    `json:"saved"`
+	Editable bool   `json:"editable"`
+}
+
+// all settings: the value the app is running with, where it came from (default, file,
+// env or flag) and what is saved in the config file
+func (b *Bindings) ConfigGet() ([]ConfigSetting, error) {
+	settings, err := config.List(b.options)
+	if err != nil {
+		return nil, err
+	}
+	list := []ConfigSetting{}
+	for _, s := range settings {
+		list = append(list, ConfigSetting(s))
+	}
+	return list, nil
+}
+
+// saves a setting to the config file (null removes it); it takes effect on the next launch
+func (b *Bindings) ConfigSet(key string, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	err = config.Save(b.options, key, encoded)
+	if errors.Is(err, config.ErrUnknownKey) || errors.Is(err, config.ErrNotEditable) || errors.Is(err, config.ErrInvalidValue) {
+		return withMessageAndDetails(ErrInvalidArgument, err.Error(), map[string]string{"key": key})
+	}
+	return err
+}
@@ -8,7 +8,6 @@ import (
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
-	"errors"
 	"fmt"
 	"net/url"
 
@@ -24,18 +23,9 @@ import (
 var clientIntent string
 var clientSecret string
 
-// trust me, this is required to keep confidentiality in case of id.sage.party compromise
-var serverECIntentPublicKey = []byte{48, 89, 48, 19, 6, 7, 42, 134, 72, 206, 61, 2, 1, 6, 8, 42, 134, 72, 206, 61, 3, 1, 7, 3, 66, 0, 4, 217, 237, 95, 239, 38, 161, 158, 33, 241, 194, 181, 97, 179, 197, 202, 84, 167, 104, 239, 1, 199, 72, 252, 31, 50, 139, 245, 233, 28, 141, 138, 135, 95, 135, 252, 168, 38, 54, 127, 229, 60, 245, 77, 211, 192, 133, 193, 86, 170, 177, 100, 113, 34, 51, 32, 151, 208, 81, 28, 28, 213, 245, 103, 180}
-
-const idProtocol = "https"
-const idHostname = "id.sage.party"
-
 func (b *Bindings) InitConnect(handover string, resource string) error {
 	if identity == nil {
-		return errors.New("Identity not loaded")
-	}
-	if currentUrl == nil || currentUrl.Host == idHostname {
-		return fmt.Errorf("this binding must not be called from %s", idHostname)
+		return withMessage(ErrNotFound, "Identity not loaded")
 	}
 	secret := make([]byte, 16)
 	if _, err := rand.Read(secret); err != nil {
@@ -50,7 +40,7 @@ func (b *Bindings) InitConnect(handover string, resource string) error {
 		"handover": {handover},
 		"resource": {resource},
 	}
-	b.ui.Navigate(fmt.Sprintf("%s://%s/#%s", idProtocol, idHostname, query.Encode()))
+	b.ui.Navigate(fmt.Sprintf("%s/#%s", b.options.GetIdentityOrigin(), query.Encode()))
 	return nil
 }
 
@@ -61,14 +51,12 @@ func (b *Bindings) ApproveConnect(secret string, approveIntent string, password
 		clientSecret = ""
 	}()
 
-	if currentUrl == nil || currentUrl.Host != idHostname {
-		return "", fmt.Errorf("this binding must be called from %s", idHostname)
-	}
 	if secret != clientSecret || secret == "" {
-		return "", errors.New("Invalid secret")
+		return "", ErrInvalidSecret
 	}
 
-	intentPK, err := x509.ParsePKIXPublicKey(serverECIntentPublicKey)
+	// pinned by the realm, so a compromised identity host can't approve intents on its own
+	intentPK, err := b.options.GetRealm().ParseConnectKey()
 	if err != nil {
 		return "", err
 	}
@@ -161,7 +149,7 @@ func (b *Bindings) ApproveConnect(secret string, approveIntent string, password
 
 	// ID challenge
 	if challenge[0] != 0x00 || challenge[1] != 0x02 {
-		return "", errors.New("Invalid challenge")
+		return "", withMessage(ErrInvalidArgument, "Invalid challenge")
 	}
 
 	response, err := identity.Sign(challenge)
@@ -178,11 +166,7 @@ func (b *Bindings) RecoverConnect(secret string, data string, password string) e
 	// be done somewhere else
 	if secret != clientSecret || secret == "" {
 		clientSecret = "" // prevent bruteforce and replay attacks
-		return errors.New("Invalid secret")
-	}
-
-	if currentUrl == nil || currentUrl.Host != idHostname {
-		return fmt.Errorf("this binding must be called from %s", idHostname)
+		return ErrInvalidSecret
 	}
 
 	sealed, err := base64.RawStdEncoding.DecodeString(data)
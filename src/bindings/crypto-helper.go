@@ -59,3 +59,55 @@ func (b *Bindings) UnsealWithKey(data string, key string) (string, error) {
 	}
 	return base64.RawStdEncoding.EncodeToString(unsealed), nil
 }
+
+func (b *Bindings) SealWithPublicKeyBlob(caller *Caller, data Data, publicKey string) (*Blob, error) {
+	decoded, err := base64.RawStdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := x509.ParsePKCS1PublicKey(decoded)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := data.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := helper.RSASeal(pk, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return b.newBlob(caller, sealed)
+}
+
+func (b *Bindings) SealWithKeyBlob(caller *Caller, data Data, key string) (*Blob, error) {
+	decoded, err := base64.RawStdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := data.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := helper.AESSeal(decoded, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return b.newBlob(caller, sealed)
+}
+
+func (b *Bindings) UnsealWithKeyBlob(caller *Caller, data Data, key string) (*Blob, error) {
+	decoded, err := base64.RawStdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := data.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	unsealed, err := helper.AESUnseal(decoded, sealed)
+	if err != nil {
+		return nil, err
+	}
+	return b.newBlob(caller, unsealed)
+}
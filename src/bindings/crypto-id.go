@@ -2,7 +2,6 @@ package bindings
 
 import (
 	"encoding/base64"
-	"errors"
 
 	"github.com/sag-enhanced/native-app/src/file"
 	id "github.com/sag-enhanced/native-app/src/identity"
@@ -29,7 +28,7 @@ func (b *Bindings) Sign2(message string) (string, error) {
 	}
 	// 0x0001 is header for signature requests
 	if len(decoded) < 2 || decoded[0] != 0x00 || decoded[1] != 0x01 {
-		return "", errors.New("Invalid message")
+		return "", withMessage(ErrInvalidArgument, "Invalid message")
 	}
 	signature, err := identity.Sign(decoded)
 	if err != nil {
@@ -71,6 +70,38 @@ func (b *Bindings) Unseal(data string) (string, error) {
 	return base64.RawStdEncoding.EncodeToString(unsealed), nil
 
 }
+func (b *Bindings) SealBlob(caller *Caller, data Data) (*Blob, error) {
+	identity, err := getIdentity(b.fm)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := data.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := identity.Seal(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return b.newBlob(caller, sealed)
+}
+
+func (b *Bindings) UnsealBlob(caller *Caller, data Data) (*Blob, error) {
+	identity, err := getIdentity(b.fm)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := data.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	unsealed, err := identity.Unseal(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return b.newBlob(caller, unsealed)
+}
+
 func getIdentity(fm *file.FileManager) (*id.Identity, error) {
 	if identity == nil {
 		id, err := id.LoadIdentity(fm)
@@ -1,10 +1,10 @@
 package bindings
 
 import (
-	"errors"
-	"fmt"
 	"os"
 	"path"
+
+	"github.com/sag-enhanced/native-app/src/file"
 )
 
 func (b *Bindings) EncryptionStatus() EncryptionStatus {
@@ -19,19 +19,19 @@ func (b *Bindings) EncryptionUnlock(password string) error {
 }
 
 func (b *Bindings) EncryptionLock() {
-	b.fm.Cipher = nil
+	b.fm.Lock()
 }
 
 func (b *Bindings) EncryptionDisable() error {
 	if b.fm.Manifest != nil && b.fm.Cipher == nil {
-		return errors.New("need to decrypt files first")
+		return withMessage(file.ErrLocked, "need to decrypt files first")
 	}
 	os.Remove(path.Join(b.options.DataDirectory, "manifest.json"))
 	errs := b.fm.UpdateFiles(true)
-	b.fm.Cipher = nil
+	b.fm.Lock()
 	b.fm.Manifest = nil
 	if len(errs) > 0 {
-		fmt.Println("Failed to update files", errs)
+		logger.Error("Failed to update files", "errors", errs)
 		return errs[0]
 	}
 	return nil
@@ -39,7 +39,7 @@ func (b *Bindings) EncryptionDisable() error {
 
 func (b *Bindings) EncryptionEnable(passwords []string) error {
 	if b.fm.Manifest != nil && b.fm.Cipher == nil {
-		return errors.New("need to decrypt files first")
+		return withMessage(file.ErrLocked, "need to decrypt files first")
 	}
 	err := b.fm.CreateKey(passwords)
 	if err != nil {
@@ -47,7 +47,7 @@ func (b *Bindings) EncryptionEnable(passwords []string) error {
 	}
 	errs := b.fm.UpdateFiles(false)
 	if len(errs) > 0 {
-		fmt.Println("Failed to update files", errs)
+		logger.Error("Failed to update files", "errors", errs)
 		return errs[0]
 	}
 	return nil
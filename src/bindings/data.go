@@ -3,7 +3,6 @@ package bindings
 import (
 	"bytes"
 	"compress/flate"
-	"fmt"
 	"io"
 	"os"
 	"path"
@@ -31,7 +30,7 @@ func (b *Bindings) Get(key string) (string, error) {
 	if err == nil {
 		os.Remove(filenameOld)
 	} else {
-		fmt.Println("Failed to write decompressed data to new file", filenameNew)
+		logger.Warn("Failed to write decompressed data to new file", "file", filenameNew)
 	}
 	return string(decompressed), nil
 }
@@ -0,0 +1,19 @@
+package bindings
+
+import "github.com/sag-enhanced/native-app/src/deeplink"
+
+type DeepLink struct {
+	Action string            `json:"action"`
+	Path   string            `json:"path"`
+	Params map[string]string `json:"params"`
+}
+
+// the deep link that is waiting for the page (signalled with a sagel event), or nil;
+// every link is only returned once
+func (b *Bindings) DeepLink() *DeepLink {
+	link := deeplink.TakePending()
+	if link == nil {
+		return nil
+	}
+	return &DeepLink{Action: link.Action, Path: link.Path, Params: link.Params}
+}
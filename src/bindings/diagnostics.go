@@ -0,0 +1,45 @@
+package bindings
+
+import (
+	"context"
+	"time"
+
+	"github.com/sag-enhanced/native-app/src/doctor"
+)
+
+type DiagnosticsCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+type DiagnosticsReport struct {
+	Time    time.Time          `json:"time"`
+	Build   uint32             `json:"build"`
+	Release uint32             `json:"release"`
+	OS      string             `json:"os"`
+	Arch    string             `json:"arch"`
+	UI      string             `json:"ui"`
+	Realm   string             `json:"realm"`
+	Checks  []DiagnosticsCheck `json:"checks"`
+}
+
+// the same checks as `sage doctor`, redacted so the report can be attached to a bug report
+func (b *Bindings) Diagnostics(ctx context.Context) *DiagnosticsReport {
+	report := doctor.Run(ctx, b.options).Redacted()
+	checks := []DiagnosticsCheck{}
+	for _, c := range report.Checks {
+		checks = append(checks, DiagnosticsCheck(c))
+	}
+	return &DiagnosticsReport{
+		Time:    report.Time,
+		Build:   report.Build,
+		Release: report.Release,
+		OS:      report.OS,
+		Arch:    report.Arch,
+		UI:      report.UI,
+		Realm:   report.Realm,
+		Checks:  checks,
+	}
+}
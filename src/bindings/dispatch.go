@@ -0,0 +1,62 @@
+package bindings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// the dispatch table is generated from the methods of *Bindings (see gen/main.go),
+// so calls don't need reflection and the frontend can ask what this build supports
+
+type binding struct {
+	manifest MethodManifest
+	call     func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error)
+}
+
+var dispatch = map[string]*binding{}
+
+func register(methodName string, b *binding) {
+	dispatch[methodName] = b
+}
+
+func decodeArg(raw []json.RawMessage, index int, name string, value any) error {
+	if err := json.Unmarshal(raw[index], value); err != nil {
+		return withMessageAndDetails(ErrInvalidArgument,
+			fmt.Sprintf("invalid argument %d (%s): %s", index, name, err),
+			map[string]any{"index": index, "name": name})
+	}
+	return nil
+}
+
+type ParamManifest struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type MethodManifest struct {
+	Name        string          `json:"name"`
+	Params      []ParamManifest `json:"params"`
+	Returns     string          `json:"returns"`
+	Cancellable bool            `json:"cancellable"`
+}
+
+type BindingsManifest struct {
+	Build   uint32           `json:"build"`
+	Release uint32           `json:"release"`
+	Methods []MethodManifest `json:"methods"`
+}
+
+func (b *Bindings) Describe() BindingsManifest {
+	methods := []MethodManifest{}
+	for _, binding := range dispatch {
+		methods = append(methods, binding.manifest)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+	return BindingsManifest{
+		Build:   b.options.Build,
+		Release: b.options.Release,
+		Methods: methods,
+	}
+}
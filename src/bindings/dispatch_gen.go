@@ -0,0 +1,985 @@
+// Code generated by gen/main.go; DO NOT EDIT.
+
+package bindings
+
+import (
+	"context"
+	"encoding/json"
+)
+
+func init() {
+	register("Alert", &binding{
+		manifest: MethodManifest{Name: "alert", Params: []ParamManifest{{"message", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var message string
+			if err := decodeArg(raw, 0, "message", &message); err != nil {
+				return nil, err
+			}
+			b.Alert(message)
+			return nil, nil
+		},
+	})
+	register("ApproveConnect", &binding{
+		manifest: MethodManifest{Name: "approveConnect", Params: []ParamManifest{{"secret", "string"}, {"approveIntent", "string"}, {"password", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var secret string
+			if err := decodeArg(raw, 0, "secret", &secret); err != nil {
+				return nil, err
+			}
+			var approveIntent string
+			if err := decodeArg(raw, 1, "approveIntent", &approveIntent); err != nil {
+				return nil, err
+			}
+			var password string
+			if err := decodeArg(raw, 2, "password", &password); err != nil {
+				return nil, err
+			}
+			return b.ApproveConnect(secret, approveIntent, password)
+		},
+	})
+	register("AuditLog", &binding{
+		manifest: MethodManifest{Name: "auditLog", Params: []ParamManifest{{"limit", "number"}}, Returns: "AuditEntry[]", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var limit int
+			if err := decodeArg(raw, 0, "limit", &limit); err != nil {
+				return nil, err
+			}
+			return b.AuditLog(limit)
+		},
+	})
+	register("BlobEndpoint", &binding{
+		manifest: MethodManifest{Name: "blobEndpoint", Params: []ParamManifest{}, Returns: "BlobEndpoint", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.BlobEndpoint(callerOf(ctx))
+		},
+	})
+	register("BlobFree", &binding{
+		manifest: MethodManifest{Name: "blobFree", Params: []ParamManifest{{"handle", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var handle string
+			if err := decodeArg(raw, 0, "handle", &handle); err != nil {
+				return nil, err
+			}
+			b.BlobFree(handle)
+			return nil, nil
+		},
+	})
+	register("BrowserDestroy", &binding{
+		manifest: MethodManifest{Name: "browserDestroy", Params: []ParamManifest{{"handle", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var handle string
+			if err := decodeArg(raw, 0, "handle", &handle); err != nil {
+				return nil, err
+			}
+			b.BrowserDestroy(handle)
+			return nil, nil
+		},
+	})
+	register("BrowserDestroyProfile", &binding{
+		manifest: MethodManifest{Name: "browserDestroyProfile", Params: []ParamManifest{{"browser", "string"}, {"profileId", "number"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var browser string
+			if err := decodeArg(raw, 0, "browser", &browser); err != nil {
+				return nil, err
+			}
+			var profileId int32
+			if err := decodeArg(raw, 1, "profileId", &profileId); err != nil {
+				return nil, err
+			}
+			return nil, b.BrowserDestroyProfile(browser, profileId)
+		},
+	})
+	register("BrowserNew", &binding{
+		manifest: MethodManifest{Name: "browserNew", Params: []ParamManifest{{"pageUrl", "string"}, {"browser", "string"}, {"proxy", "string | null"}, {"profileId", "number"}}, Returns: "string", Cancellable: true},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var pageUrl string
+			if err := decodeArg(raw, 0, "pageUrl", &pageUrl); err != nil {
+				return nil, err
+			}
+			var browser string
+			if err := decodeArg(raw, 1, "browser", &browser); err != nil {
+				return nil, err
+			}
+			var proxy *string
+			if err := decodeArg(raw, 2, "proxy", &proxy); err != nil {
+				return nil, err
+			}
+			var profileId int32
+			if err := decodeArg(raw, 3, "profileId", &profileId); err != nil {
+				return nil, err
+			}
+			return b.BrowserNew(ctx, pageUrl, browser, proxy, profileId)
+		},
+	})
+	register("Build", &binding{
+		manifest: MethodManifest{Name: "build", Params: []ParamManifest{}, Returns: "number", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.Build(), nil
+		},
+	})
+	register("Cancel", &binding{
+		manifest: MethodManifest{Name: "cancel", Params: []ParamManifest{{"callId", "number"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var callId int
+			if err := decodeArg(raw, 0, "callId", &callId); err != nil {
+				return nil, err
+			}
+			b.Cancel(callId)
+			return nil, nil
+		},
+	})
+	register("ConfigGet", &binding{
+		manifest: MethodManifest{Name: "configGet", Params: []ParamManifest{}, Returns: "ConfigSetting[]", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.ConfigGet()
+		},
+	})
+	register("ConfigSet", &binding{
+		manifest: MethodManifest{Name: "configSet", Params: []ParamManifest{{"key", "string"}, {"value", "any"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var key string
+			if err := decodeArg(raw, 0, "key", &key); err != nil {
+				return nil, err
+			}
+			var value any
+			if err := decodeArg(raw, 1, "value", &value); err != nil {
+				return nil, err
+			}
+			return nil, b.ConfigSet(key, value)
+		},
+	})
+	register("DeepLink", &binding{
+		manifest: MethodManifest{Name: "deepLink", Params: []ParamManifest{}, Returns: "DeepLink | null", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.DeepLink(), nil
+		},
+	})
+	register("Describe", &binding{
+		manifest: MethodManifest{Name: "describe", Params: []ParamManifest{}, Returns: "BindingsManifest", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.Describe(), nil
+		},
+	})
+	register("Diagnostics", &binding{
+		manifest: MethodManifest{Name: "diagnostics", Params: []ParamManifest{}, Returns: "DiagnosticsReport | null", Cancellable: true},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.Diagnostics(ctx), nil
+		},
+	})
+	register("EncryptionDisable", &binding{
+		manifest: MethodManifest{Name: "encryptionDisable", Params: []ParamManifest{}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return nil, b.EncryptionDisable()
+		},
+	})
+	register("EncryptionEnable", &binding{
+		manifest: MethodManifest{Name: "encryptionEnable", Params: []ParamManifest{{"passwords", "string[]"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var passwords []string
+			if err := decodeArg(raw, 0, "passwords", &passwords); err != nil {
+				return nil, err
+			}
+			return nil, b.EncryptionEnable(passwords)
+		},
+	})
+	register("EncryptionLock", &binding{
+		manifest: MethodManifest{Name: "encryptionLock", Params: []ParamManifest{}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			b.EncryptionLock()
+			return nil, nil
+		},
+	})
+	register("EncryptionStatus", &binding{
+		manifest: MethodManifest{Name: "encryptionStatus", Params: []ParamManifest{}, Returns: "EncryptionStatus", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.EncryptionStatus(), nil
+		},
+	})
+	register("EncryptionUnlock", &binding{
+		manifest: MethodManifest{Name: "encryptionUnlock", Params: []ParamManifest{{"password", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var password string
+			if err := decodeArg(raw, 0, "password", &password); err != nil {
+				return nil, err
+			}
+			return nil, b.EncryptionUnlock(password)
+		},
+	})
+	register("Ext", &binding{
+		manifest: MethodManifest{Name: "ext", Params: []ParamManifest{{"browser", "string"}}, Returns: "Record<string, string> | null", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var browser string
+			if err := decodeArg(raw, 0, "browser", &browser); err != nil {
+				return nil, err
+			}
+			return b.Ext(browser)
+		},
+	})
+	register("ExtGetManifest", &binding{
+		manifest: MethodManifest{Name: "extGetManifest", Params: []ParamManifest{{"name", "string"}, {"browser", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			var browser string
+			if err := decodeArg(raw, 1, "browser", &browser); err != nil {
+				return nil, err
+			}
+			return b.ExtGetManifest(name, browser)
+		},
+	})
+	register("ExtInstall", &binding{
+		manifest: MethodManifest{Name: "extInstall", Params: []ParamManifest{{"name", "string"}, {"browser", "string"}, {"download", "string"}}, Returns: "void", Cancellable: true},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			var browser string
+			if err := decodeArg(raw, 1, "browser", &browser); err != nil {
+				return nil, err
+			}
+			var download string
+			if err := decodeArg(raw, 2, "download", &download); err != nil {
+				return nil, err
+			}
+			return nil, b.ExtInstall(ctx, name, browser, download)
+		},
+	})
+	register("ExtSetManifest", &binding{
+		manifest: MethodManifest{Name: "extSetManifest", Params: []ParamManifest{{"name", "string"}, {"browser", "string"}, {"manifest", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			var browser string
+			if err := decodeArg(raw, 1, "browser", &browser); err != nil {
+				return nil, err
+			}
+			var manifest string
+			if err := decodeArg(raw, 2, "manifest", &manifest); err != nil {
+				return nil, err
+			}
+			return nil, b.ExtSetManifest(name, browser, manifest)
+		},
+	})
+	register("ExtUninstall", &binding{
+		manifest: MethodManifest{Name: "extUninstall", Params: []ParamManifest{{"name", "string"}, {"browser", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			var browser string
+			if err := decodeArg(raw, 1, "browser", &browser); err != nil {
+				return nil, err
+			}
+			return nil, b.ExtUninstall(name, browser)
+		},
+	})
+	register("FsDeleteFile", &binding{
+		manifest: MethodManifest{Name: "fsDeleteFile", Params: []ParamManifest{{"filename", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var filename string
+			if err := decodeArg(raw, 0, "filename", &filename); err != nil {
+				return nil, err
+			}
+			return nil, b.FsDeleteFile(filename)
+		},
+	})
+	register("FsListFiles", &binding{
+		manifest: MethodManifest{Name: "fsListFiles", Params: []ParamManifest{{"dirname", "string"}}, Returns: "string[]", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var dirname string
+			if err := decodeArg(raw, 0, "dirname", &dirname); err != nil {
+				return nil, err
+			}
+			return b.FsListFiles(dirname)
+		},
+	})
+	register("FsMkdir", &binding{
+		manifest: MethodManifest{Name: "fsMkdir", Params: []ParamManifest{{"dirname", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var dirname string
+			if err := decodeArg(raw, 0, "dirname", &dirname); err != nil {
+				return nil, err
+			}
+			return nil, b.FsMkdir(dirname)
+		},
+	})
+	register("FsReadBlob", &binding{
+		manifest: MethodManifest{Name: "fsReadBlob", Params: []ParamManifest{{"filename", "string"}}, Returns: "Blob | null", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var filename string
+			if err := decodeArg(raw, 0, "filename", &filename); err != nil {
+				return nil, err
+			}
+			return b.FsReadBlob(callerOf(ctx), filename)
+		},
+	})
+	register("FsReadFile", &binding{
+		manifest: MethodManifest{Name: "fsReadFile", Params: []ParamManifest{{"filename", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var filename string
+			if err := decodeArg(raw, 0, "filename", &filename); err != nil {
+				return nil, err
+			}
+			return b.FsReadFile(filename)
+		},
+	})
+	register("FsWriteBlob", &binding{
+		manifest: MethodManifest{Name: "fsWriteBlob", Params: []ParamManifest{{"filename", "string"}, {"data", "Data"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var filename string
+			if err := decodeArg(raw, 0, "filename", &filename); err != nil {
+				return nil, err
+			}
+			var data Data
+			if err := decodeArg(raw, 1, "data", &data); err != nil {
+				return nil, err
+			}
+			return nil, b.FsWriteBlob(filename, data)
+		},
+	})
+	register("FsWriteFile", &binding{
+		manifest: MethodManifest{Name: "fsWriteFile", Params: []ParamManifest{{"filename", "string"}, {"content", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var filename string
+			if err := decodeArg(raw, 0, "filename", &filename); err != nil {
+				return nil, err
+			}
+			var content string
+			if err := decodeArg(raw, 1, "content", &content); err != nil {
+				return nil, err
+			}
+			return nil, b.FsWriteFile(filename, content)
+		},
+	})
+	register("Get", &binding{
+		manifest: MethodManifest{Name: "get", Params: []ParamManifest{{"key", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var key string
+			if err := decodeArg(raw, 0, "key", &key); err != nil {
+				return nil, err
+			}
+			return b.Get(key)
+		},
+	})
+	register("HandleDestroyAll", &binding{
+		manifest: MethodManifest{Name: "handleDestroyAll", Params: []ParamManifest{}, Returns: "number", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.HandleDestroyAll(), nil
+		},
+	})
+	register("HandleList", &binding{
+		manifest: MethodManifest{Name: "handleList", Params: []ParamManifest{}, Returns: "HandleInfo[]", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.HandleList(), nil
+		},
+	})
+	register("HttpClient", &binding{
+		manifest: MethodManifest{Name: "httpClient", Params: []ParamManifest{{"proxyUrl", "string | null"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var proxyUrl *string
+			if err := decodeArg(raw, 0, "proxyUrl", &proxyUrl); err != nil {
+				return nil, err
+			}
+			return b.HttpClient(callerOf(ctx), proxyUrl)
+		},
+	})
+	register("HttpCookie", &binding{
+		manifest: MethodManifest{Name: "httpCookie", Params: []ParamManifest{{"handle", "string"}, {"domain", "string"}, {"name", "string"}, {"value", "string | null"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var handle string
+			if err := decodeArg(raw, 0, "handle", &handle); err != nil {
+				return nil, err
+			}
+			var domain string
+			if err := decodeArg(raw, 1, "domain", &domain); err != nil {
+				return nil, err
+			}
+			var name string
+			if err := decodeArg(raw, 2, "name", &name); err != nil {
+				return nil, err
+			}
+			var value *string
+			if err := decodeArg(raw, 3, "value", &value); err != nil {
+				return nil, err
+			}
+			return b.HttpCookie(handle, domain, name, value)
+		},
+	})
+	register("HttpDestroy", &binding{
+		manifest: MethodManifest{Name: "httpDestroy", Params: []ParamManifest{{"handle", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var handle string
+			if err := decodeArg(raw, 0, "handle", &handle); err != nil {
+				return nil, err
+			}
+			b.HttpDestroy(handle)
+			return nil, nil
+		},
+	})
+	register("HttpFetch", &binding{
+		manifest: MethodManifest{Name: "httpFetch", Params: []ParamManifest{{"handle", "string"}, {"request", "HTTPFetchRequest"}}, Returns: "HTTPFetchResponse | null", Cancellable: true},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var handle string
+			if err := decodeArg(raw, 0, "handle", &handle); err != nil {
+				return nil, err
+			}
+			var request HTTPFetchRequest
+			if err := decodeArg(raw, 1, "request", &request); err != nil {
+				return nil, err
+			}
+			return b.HttpFetch(ctx, handle, request)
+		},
+	})
+	register("HttpRequest", &binding{
+		manifest: MethodManifest{Name: "httpRequest", Params: []ParamManifest{{"handle", "string"}, {"method", "string"}, {"url", "string"}, {"headers", "Record<string, string>"}, {"body", "string"}}, Returns: "HTTPResponse | null", Cancellable: true},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var handle string
+			if err := decodeArg(raw, 0, "handle", &handle); err != nil {
+				return nil, err
+			}
+			var method string
+			if err := decodeArg(raw, 1, "method", &method); err != nil {
+				return nil, err
+			}
+			var url string
+			if err := decodeArg(raw, 2, "url", &url); err != nil {
+				return nil, err
+			}
+			var headers map[string]string
+			if err := decodeArg(raw, 3, "headers", &headers); err != nil {
+				return nil, err
+			}
+			var body string
+			if err := decodeArg(raw, 4, "body", &body); err != nil {
+				return nil, err
+			}
+			return b.HttpRequest(ctx, handle, method, url, headers, body)
+		},
+	})
+	register("HttpRequestBlob", &binding{
+		manifest: MethodManifest{Name: "httpRequestBlob", Params: []ParamManifest{{"handle", "string"}, {"method", "string"}, {"url", "string"}, {"headers", "Record<string, string>"}, {"body", "Data"}}, Returns: "HTTPBlobResponse | null", Cancellable: true},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var handle string
+			if err := decodeArg(raw, 0, "handle", &handle); err != nil {
+				return nil, err
+			}
+			var method string
+			if err := decodeArg(raw, 1, "method", &method); err != nil {
+				return nil, err
+			}
+			var url string
+			if err := decodeArg(raw, 2, "url", &url); err != nil {
+				return nil, err
+			}
+			var headers map[string]string
+			if err := decodeArg(raw, 3, "headers", &headers); err != nil {
+				return nil, err
+			}
+			var body Data
+			if err := decodeArg(raw, 4, "body", &body); err != nil {
+				return nil, err
+			}
+			return b.HttpRequestBlob(ctx, handle, method, url, headers, body)
+		},
+	})
+	register("HttpSetRedirects", &binding{
+		manifest: MethodManifest{Name: "httpSetRedirects", Params: []ParamManifest{{"handle", "string"}, {"redirects", "number"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var handle string
+			if err := decodeArg(raw, 0, "handle", &handle); err != nil {
+				return nil, err
+			}
+			var redirects int
+			if err := decodeArg(raw, 1, "redirects", &redirects); err != nil {
+				return nil, err
+			}
+			return nil, b.HttpSetRedirects(handle, redirects)
+		},
+	})
+	register("Id", &binding{
+		manifest: MethodManifest{Name: "id", Params: []ParamManifest{}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.Id()
+		},
+	})
+	register("Info", &binding{
+		manifest: MethodManifest{Name: "info", Params: []ParamManifest{}, Returns: "Record<string, any>", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.Info()
+		},
+	})
+	register("InitConnect", &binding{
+		manifest: MethodManifest{Name: "initConnect", Params: []ParamManifest{{"handover", "string"}, {"resource", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var handover string
+			if err := decodeArg(raw, 0, "handover", &handover); err != nil {
+				return nil, err
+			}
+			var resource string
+			if err := decodeArg(raw, 1, "resource", &resource); err != nil {
+				return nil, err
+			}
+			return nil, b.InitConnect(handover, resource)
+		},
+	})
+	register("Notify", &binding{
+		manifest: MethodManifest{Name: "notify", Params: []ParamManifest{{"title", "string"}, {"message", "string"}, {"alert", "boolean"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var title string
+			if err := decodeArg(raw, 0, "title", &title); err != nil {
+				return nil, err
+			}
+			var message string
+			if err := decodeArg(raw, 1, "message", &message); err != nil {
+				return nil, err
+			}
+			var alert bool
+			if err := decodeArg(raw, 2, "alert", &alert); err != nil {
+				return nil, err
+			}
+			return nil, b.Notify(title, message, alert)
+		},
+	})
+	register("Offline", &binding{
+		manifest: MethodManifest{Name: "offline", Params: []ParamManifest{}, Returns: "OfflineStatus", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.Offline(), nil
+		},
+	})
+	register("Open", &binding{
+		manifest: MethodManifest{Name: "open", Params: []ParamManifest{{"target", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var target string
+			if err := decodeArg(raw, 0, "target", &target); err != nil {
+				return nil, err
+			}
+			return nil, b.Open(target)
+		},
+	})
+	register("ProxyDestroy", &binding{
+		manifest: MethodManifest{Name: "proxyDestroy", Params: []ParamManifest{{"handle", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var handle string
+			if err := decodeArg(raw, 0, "handle", &handle); err != nil {
+				return nil, err
+			}
+			return nil, b.ProxyDestroy(handle)
+		},
+	})
+	register("ProxyNew", &binding{
+		manifest: MethodManifest{Name: "proxyNew", Params: []ParamManifest{{"proxyUrl", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var proxyUrl string
+			if err := decodeArg(raw, 0, "proxyUrl", &proxyUrl); err != nil {
+				return nil, err
+			}
+			return b.ProxyNew(callerOf(ctx), proxyUrl)
+		},
+	})
+	register("Quit", &binding{
+		manifest: MethodManifest{Name: "quit", Params: []ParamManifest{}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			b.Quit()
+			return nil, nil
+		},
+	})
+	register("Read", &binding{
+		manifest: MethodManifest{Name: "read", Params: []ParamManifest{{"filterText", "string"}, {"filter", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var filterText string
+			if err := decodeArg(raw, 0, "filterText", &filterText); err != nil {
+				return nil, err
+			}
+			var filter string
+			if err := decodeArg(raw, 1, "filter", &filter); err != nil {
+				return nil, err
+			}
+			return b.Read(filterText, filter)
+		},
+	})
+	register("RecoverConnect", &binding{
+		manifest: MethodManifest{Name: "recoverConnect", Params: []ParamManifest{{"secret", "string"}, {"data", "string"}, {"password", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var secret string
+			if err := decodeArg(raw, 0, "secret", &secret); err != nil {
+				return nil, err
+			}
+			var data string
+			if err := decodeArg(raw, 1, "data", &data); err != nil {
+				return nil, err
+			}
+			var password string
+			if err := decodeArg(raw, 2, "password", &password); err != nil {
+				return nil, err
+			}
+			return nil, b.RecoverConnect(secret, data, password)
+		},
+	})
+	register("Save", &binding{
+		manifest: MethodManifest{Name: "save", Params: []ParamManifest{{"filename", "string"}, {"data", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var filename string
+			if err := decodeArg(raw, 0, "filename", &filename); err != nil {
+				return nil, err
+			}
+			var data string
+			if err := decodeArg(raw, 1, "data", &data); err != nil {
+				return nil, err
+			}
+			return nil, b.Save(filename, data)
+		},
+	})
+	register("Save2", &binding{
+		manifest: MethodManifest{Name: "save2", Params: []ParamManifest{{"filename", "string"}, {"data", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var filename string
+			if err := decodeArg(raw, 0, "filename", &filename); err != nil {
+				return nil, err
+			}
+			var data string
+			if err := decodeArg(raw, 1, "data", &data); err != nil {
+				return nil, err
+			}
+			return nil, b.Save2(filename, data)
+		},
+	})
+	register("SaveBlob", &binding{
+		manifest: MethodManifest{Name: "saveBlob", Params: []ParamManifest{{"filename", "string"}, {"data", "Data"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var filename string
+			if err := decodeArg(raw, 0, "filename", &filename); err != nil {
+				return nil, err
+			}
+			var data Data
+			if err := decodeArg(raw, 1, "data", &data); err != nil {
+				return nil, err
+			}
+			return nil, b.SaveBlob(filename, data)
+		},
+	})
+	register("Seal", &binding{
+		manifest: MethodManifest{Name: "seal", Params: []ParamManifest{{"data", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var data string
+			if err := decodeArg(raw, 0, "data", &data); err != nil {
+				return nil, err
+			}
+			return b.Seal(data)
+		},
+	})
+	register("SealBlob", &binding{
+		manifest: MethodManifest{Name: "sealBlob", Params: []ParamManifest{{"data", "Data"}}, Returns: "Blob | null", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var data Data
+			if err := decodeArg(raw, 0, "data", &data); err != nil {
+				return nil, err
+			}
+			return b.SealBlob(callerOf(ctx), data)
+		},
+	})
+	register("SealWithKey", &binding{
+		manifest: MethodManifest{Name: "sealWithKey", Params: []ParamManifest{{"data", "string"}, {"key", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var data string
+			if err := decodeArg(raw, 0, "data", &data); err != nil {
+				return nil, err
+			}
+			var key string
+			if err := decodeArg(raw, 1, "key", &key); err != nil {
+				return nil, err
+			}
+			return b.SealWithKey(data, key)
+		},
+	})
+	register("SealWithKeyBlob", &binding{
+		manifest: MethodManifest{Name: "sealWithKeyBlob", Params: []ParamManifest{{"data", "Data"}, {"key", "string"}}, Returns: "Blob | null", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var data Data
+			if err := decodeArg(raw, 0, "data", &data); err != nil {
+				return nil, err
+			}
+			var key string
+			if err := decodeArg(raw, 1, "key", &key); err != nil {
+				return nil, err
+			}
+			return b.SealWithKeyBlob(callerOf(ctx), data, key)
+		},
+	})
+	register("SealWithPublicKey", &binding{
+		manifest: MethodManifest{Name: "sealWithPublicKey", Params: []ParamManifest{{"data", "string"}, {"publicKey", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var data string
+			if err := decodeArg(raw, 0, "data", &data); err != nil {
+				return nil, err
+			}
+			var publicKey string
+			if err := decodeArg(raw, 1, "publicKey", &publicKey); err != nil {
+				return nil, err
+			}
+			return b.SealWithPublicKey(data, publicKey)
+		},
+	})
+	register("SealWithPublicKeyBlob", &binding{
+		manifest: MethodManifest{Name: "sealWithPublicKeyBlob", Params: []ParamManifest{{"data", "Data"}, {"publicKey", "string"}}, Returns: "Blob | null", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var data Data
+			if err := decodeArg(raw, 0, "data", &data); err != nil {
+				return nil, err
+			}
+			var publicKey string
+			if err := decodeArg(raw, 1, "publicKey", &publicKey); err != nil {
+				return nil, err
+			}
+			return b.SealWithPublicKeyBlob(callerOf(ctx), data, publicKey)
+		},
+	})
+	register("ServerDestroy", &binding{
+		manifest: MethodManifest{Name: "serverDestroy", Params: []ParamManifest{}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			b.ServerDestroy(callerOf(ctx))
+			return nil, nil
+		},
+	})
+	register("ServerNew", &binding{
+		manifest: MethodManifest{Name: "serverNew", Params: []ParamManifest{}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.ServerNew(callerOf(ctx))
+		},
+	})
+	register("ServerRespond", &binding{
+		manifest: MethodManifest{Name: "serverRespond", Params: []ParamManifest{{"requestId", "number"}, {"statusCode", "number"}, {"headers", "Record<string, string>"}, {"body", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var requestId int
+			if err := decodeArg(raw, 0, "requestId", &requestId); err != nil {
+				return nil, err
+			}
+			var statusCode int
+			if err := decodeArg(raw, 1, "statusCode", &statusCode); err != nil {
+				return nil, err
+			}
+			var headers map[string]string
+			if err := decodeArg(raw, 2, "headers", &headers); err != nil {
+				return nil, err
+			}
+			var body string
+			if err := decodeArg(raw, 3, "body", &body); err != nil {
+				return nil, err
+			}
+			return nil, b.ServerRespond(requestId, statusCode, headers, body)
+		},
+	})
+	register("Set", &binding{
+		manifest: MethodManifest{Name: "set", Params: []ParamManifest{{"key", "string"}, {"value", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var key string
+			if err := decodeArg(raw, 0, "key", &key); err != nil {
+				return nil, err
+			}
+			var value string
+			if err := decodeArg(raw, 1, "value", &value); err != nil {
+				return nil, err
+			}
+			return nil, b.Set(key, value)
+		},
+	})
+	register("SetUrl", &binding{
+		manifest: MethodManifest{Name: "setUrl", Params: []ParamManifest{{"currentPageUrl", "string"}, {"secret", "string"}}, Returns: "void", Cancellable: true},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var currentPageUrl string
+			if err := decodeArg(raw, 0, "currentPageUrl", &currentPageUrl); err != nil {
+				return nil, err
+			}
+			var secret string
+			if err := decodeArg(raw, 1, "secret", &secret); err != nil {
+				return nil, err
+			}
+			return nil, b.SetUrl(ctx, currentPageUrl, secret)
+		},
+	})
+	register("Sign2", &binding{
+		manifest: MethodManifest{Name: "sign2", Params: []ParamManifest{{"message", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var message string
+			if err := decodeArg(raw, 0, "message", &message); err != nil {
+				return nil, err
+			}
+			return b.Sign2(message)
+		},
+	})
+	register("Start", &binding{
+		manifest: MethodManifest{Name: "start", Params: []ParamManifest{}, Returns: "number", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.Start(), nil
+		},
+	})
+	register("SteamPatch", &binding{
+		manifest: MethodManifest{Name: "steamPatch", Params: []ParamManifest{{"js", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var js string
+			if err := decodeArg(raw, 0, "js", &js); err != nil {
+				return nil, err
+			}
+			return nil, b.SteamPatch(js)
+		},
+	})
+	register("SteamRun", &binding{
+		manifest: MethodManifest{Name: "steamRun", Params: []ParamManifest{}, Returns: "void", Cancellable: true},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return nil, b.SteamRun(ctx)
+		},
+	})
+	register("Unseal", &binding{
+		manifest: MethodManifest{Name: "unseal", Params: []ParamManifest{{"data", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var data string
+			if err := decodeArg(raw, 0, "data", &data); err != nil {
+				return nil, err
+			}
+			return b.Unseal(data)
+		},
+	})
+	register("UnsealBlob", &binding{
+		manifest: MethodManifest{Name: "unsealBlob", Params: []ParamManifest{{"data", "Data"}}, Returns: "Blob | null", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var data Data
+			if err := decodeArg(raw, 0, "data", &data); err != nil {
+				return nil, err
+			}
+			return b.UnsealBlob(callerOf(ctx), data)
+		},
+	})
+	register("UnsealWithKey", &binding{
+		manifest: MethodManifest{Name: "unsealWithKey", Params: []ParamManifest{{"data", "string"}, {"key", "string"}}, Returns: "string", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var data string
+			if err := decodeArg(raw, 0, "data", &data); err != nil {
+				return nil, err
+			}
+			var key string
+			if err := decodeArg(raw, 1, "key", &key); err != nil {
+				return nil, err
+			}
+			return b.UnsealWithKey(data, key)
+		},
+	})
+	register("UnsealWithKeyBlob", &binding{
+		manifest: MethodManifest{Name: "unsealWithKeyBlob", Params: []ParamManifest{{"data", "Data"}, {"key", "string"}}, Returns: "Blob | null", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var data Data
+			if err := decodeArg(raw, 0, "data", &data); err != nil {
+				return nil, err
+			}
+			var key string
+			if err := decodeArg(raw, 1, "key", &key); err != nil {
+				return nil, err
+			}
+			return b.UnsealWithKeyBlob(callerOf(ctx), data, key)
+		},
+	})
+	register("WindowClose", &binding{
+		manifest: MethodManifest{Name: "windowClose", Params: []ParamManifest{{"name", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			return nil, b.WindowClose(name)
+		},
+	})
+	register("WindowList", &binding{
+		manifest: MethodManifest{Name: "windowList", Params: []ParamManifest{}, Returns: "string[]", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.WindowList()
+		},
+	})
+	register("WindowMinimize", &binding{
+		manifest: MethodManifest{Name: "windowMinimize", Params: []ParamManifest{{"name", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			return nil, b.WindowMinimize(name)
+		},
+	})
+	register("WindowOpen", &binding{
+		manifest: MethodManifest{Name: "windowOpen", Params: []ParamManifest{{"name", "string"}, {"path", "string"}, {"options", "WindowOptions"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			var path string
+			if err := decodeArg(raw, 1, "path", &path); err != nil {
+				return nil, err
+			}
+			var options WindowOptions
+			if err := decodeArg(raw, 2, "options", &options); err != nil {
+				return nil, err
+			}
+			return nil, b.WindowOpen(name, path, options)
+		},
+	})
+	register("WindowRestore", &binding{
+		manifest: MethodManifest{Name: "windowRestore", Params: []ParamManifest{{"name", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			return nil, b.WindowRestore(name)
+		},
+	})
+	register("WindowSetAlwaysOnTop", &binding{
+		manifest: MethodManifest{Name: "windowSetAlwaysOnTop", Params: []ParamManifest{{"name", "string"}, {"enabled", "boolean"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			var enabled bool
+			if err := decodeArg(raw, 1, "enabled", &enabled); err != nil {
+				return nil, err
+			}
+			return nil, b.WindowSetAlwaysOnTop(name, enabled)
+		},
+	})
+	register("WindowSetSize", &binding{
+		manifest: MethodManifest{Name: "windowSetSize", Params: []ParamManifest{{"name", "string"}, {"width", "number"}, {"height", "number"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			var width int
+			if err := decodeArg(raw, 1, "width", &width); err != nil {
+				return nil, err
+			}
+			var height int
+			if err := decodeArg(raw, 2, "height", &height); err != nil {
+				return nil, err
+			}
+			return nil, b.WindowSetSize(name, width, height)
+		},
+	})
+	register("WindowSetTitle", &binding{
+		manifest: MethodManifest{Name: "windowSetTitle", Params: []ParamManifest{{"name", "string"}, {"title", "string"}}, Returns: "void", Cancellable: false},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			var name string
+			if err := decodeArg(raw, 0, "name", &name); err != nil {
+				return nil, err
+			}
+			var title string
+			if err := decodeArg(raw, 1, "title", &title); err != nil {
+				return nil, err
+			}
+			return nil, b.WindowSetTitle(name, title)
+		},
+	})
+}
@@ -0,0 +1,19 @@
+// Code generated by gen/main.go; DO NOT EDIT.
+
+//go:build !darwin || CI
+
+package bindings
+
+import (
+	"context"
+	"encoding/json"
+)
+
+func init() {
+	register("ScreenshotQR", &binding{
+		manifest: MethodManifest{Name: "screenshotQR", Params: []ParamManifest{}, Returns: "string[]", Cancellable: true},
+		call: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {
+			return b.ScreenshotQR(ctx)
+		},
+	})
+}
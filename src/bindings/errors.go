@@ -0,0 +1,119 @@
+package bindings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	browserAPI "github.com/sag-enhanced/native-app/src/browser"
+	"github.com/sag-enhanced/native-app/src/file"
+	id "github.com/sag-enhanced/native-app/src/identity"
+	"github.com/sag-enhanced/native-app/src/steam"
+	"github.com/sag-enhanced/native-app/src/ui"
+	"github.com/sqweek/dialog"
+)
+
+var (
+	ErrForbidden        = errors.New("forbidden")
+	ErrInvalidHandle    = errors.New("invalid handle")
+	ErrNotFound         = errors.New("not found")
+	ErrInvalidArgument  = errors.New("invalid argument")
+	ErrForbiddenHost    = errors.New("This host is not allowed to be accessed.")
+	ErrLocalProxyOnly   = errors.New("Only local proxies are allowed.")
+	ErrInvalidSecret    = errors.New("Invalid secret")
+	ErrTooManyRedirects = errors.New("too many redirects")
+	errInternal         = errors.New("internal error")
+	errShuttingDown     = errors.New("app is shutting down")
+)
+
+// codes the frontend can rely on, instead of string-matching messages
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrForbidden, "forbidden"},
+	{ErrInvalidHandle, "invalid_handle"},
+	{ErrNotFound, "not_found"},
+	{ErrInvalidArgument, "invalid_argument"},
+	{ErrForbiddenHost, "forbidden_host"},
+	{ErrLocalProxyOnly, "local_proxy_only"},
+	{ErrInvalidSecret, "invalid_secret"},
+	{ErrTooManyRedirects, "too_many_redirects"},
+	{errInternal, "internal"},
+	{errShuttingDown, "shutting_down"},
+	{errNavigated, "navigated"},
+	{file.ErrClosed, "shutting_down"},
+	{file.ErrLocked, "locked"},
+	{file.ErrInvalidPassword, "invalid_password"},
+	{file.ErrNoManifest, "not_encrypted"},
+	{file.ErrCorrupted, "corrupted"},
+	{id.ErrInvalidKey, "invalid_identity"},
+	{steam.ErrSteamNotFound, "steam_not_found"},
+	{steam.ErrSteamDataNotFound, "steam_not_found"},
+	{browserAPI.ErrBrowserNotFound, "browser_not_found"},
+	{dialog.ErrCancelled, "dialog_cancelled"},
+	{ui.ErrNoWindow, "not_found"},
+	{errors.ErrUnsupported, "unsupported"},
+	{context.Canceled, "cancelled"},
+	{context.DeadlineExceeded, "timeout"},
+	{os.ErrNotExist, "not_found"},
+	{os.ErrPermission, "permission_denied"},
+}
+
+// matches one of the errors above, but with a more specific message and/or details
+type taggedError struct {
+	kind    error
+	message string
+	details any
+}
+
+func (e *taggedError) Error() string {
+	return e.message
+}
+
+func (e *taggedError) Unwrap() error {
+	return e.kind
+}
+
+func withMessage(kind error, message string) error {
+	return &taggedError{kind: kind, message: message}
+}
+
+func withDetails(kind error, details any) error {
+	return &taggedError{kind: kind, message: kind.Error(), details: details}
+}
+
+func withMessageAndDetails(kind error, message string, details any) error {
+	return &taggedError{kind: kind, message: message, details: details}
+}
+
+// rejections are objects that the frontend turns into an Error with extra fields
+type rpcError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+func errorCode(err error) string {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return "unknown"
+}
+
+func encodeError(err error) json.RawMessage {
+	rpcErr := rpcError{Code: errorCode(err), Message: err.Error()}
+	var tagged *taggedError
+	if errors.As(err, &tagged) {
+		rpcErr.Details = tagged.details
+	}
+	encoded, err := json.Marshal(rpcErr)
+	if err != nil {
+		rpcErr.Details = nil
+		encoded, _ = json.Marshal(rpcErr)
+	}
+	return encoded
+}
@@ -2,7 +2,6 @@ package bindings
 
 import (
 	"encoding/base64"
-	"errors"
 	"os"
 	"path"
 	"strings"
@@ -43,6 +42,32 @@ func (b *Bindings) FsWriteFile(filename string, content string) error {
 	return os.WriteFile(path, []byte(content), 0644)
 }
 
+func (b *Bindings) FsReadBlob(caller *Caller, filename string) (*Blob, error) {
+	path, err := b.fsValidateFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return b.newBlob(caller, content)
+}
+
+func (b *Bindings) FsWriteBlob(filename string, data Data) error {
+	path, err := b.fsValidateFilename(filename)
+	if err != nil {
+		return err
+	}
+
+	content, err := data.Bytes()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
 func (b *Bindings) FsDeleteFile(filename string) error {
 	path, err := b.fsValidateFilename(filename)
 	if err != nil {
@@ -82,12 +107,12 @@ func (b *Bindings) FsMkdir(dirname string) error {
 func (b *Bindings) fsValidateFilename(filename string) (string, error) {
 	cleaned := path.Clean(strings.ReplaceAll(filename, "\\", "/"))
 	if cleaned != filename {
-		return "", errors.New("Invalid filename")
+		return "", withDetails(ErrInvalidArgument, map[string]string{"filename": filename})
 	}
 
 	realName := path.Clean(path.Join(b.options.DataDirectory, "files", filename))
 	if !strings.HasPrefix(realName, b.options.DataDirectory) {
-		return "", errors.New("Invalid filename")
+		return "", withDetails(ErrInvalidArgument, map[string]string{"filename": filename})
 	}
 	return realName, nil
 }
@@ -0,0 +1,360 @@
+// generates the static dispatch table and TypeScript definitions for all bindings
+// run via `go generate` in the bindings package
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// methods of *Bindings that must never be callable from the frontend
+var ignored = map[string]bool{
+	"BindHandler":       true,
+	"HangHandler":       true,
+	"NavigationHandler": true,
+	"Shutdown":          true,
+}
+
+type param struct {
+	name   string
+	goType string
+	tsType string
+}
+
+type method struct {
+	name        string
+	params      []param
+	cancellable bool
+	// takes the *Caller, filled in by the dispatcher
+	caller  bool
+	results []string
+	returns string
+}
+
+type group struct {
+	constraint string
+	methods    []method
+}
+
+func main() {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		fail(err)
+	}
+
+	structs := map[string]*ast.StructType{}
+	parsed := map[string]*ast.File{}
+	for _, filename := range files {
+		if strings.HasSuffix(filename, "_gen.go") || strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			fail(err)
+		}
+		parsed[filename] = file
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						structs[ts.Name.Name] = st
+					}
+				}
+			}
+		}
+	}
+
+	ts := &tsWriter{structs: structs, emitted: map[string]bool{}}
+	groups := map[string]*group{}
+	for _, filename := range files {
+		file, ok := parsed[filename]
+		if !ok {
+			continue
+		}
+		expr := buildConstraint(file)
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || !fn.Name.IsExported() || ignored[fn.Name.Name] {
+				continue
+			}
+			if star, ok := fn.Recv.List[0].Type.(*ast.StarExpr); !ok || exprString(star.X) != "Bindings" {
+				continue
+			}
+			m := parseMethod(fn, ts)
+			if groups[expr] == nil {
+				groups[expr] = &group{constraint: expr}
+			}
+			groups[expr].methods = append(groups[expr].methods, m)
+		}
+	}
+
+	keys := []string{}
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var all []method
+	for _, key := range keys {
+		g := groups[key]
+		sort.Slice(g.methods, func(i, j int) bool { return g.methods[i].name < g.methods[j].name })
+		all = append(all, g.methods...)
+		writeDispatch(g)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].name < all[j].name })
+	writeDefinitions(all, ts)
+}
+
+func parseMethod(fn *ast.FuncDecl, ts *tsWriter) method {
+	m := method{name: fn.Name.Name}
+	for i, field := range fn.Type.Params.List {
+		goType := exprString(field.Type)
+		if goType == "context.Context" {
+			if i != 0 {
+				fail(fmt.Errorf("%s: context.Context must be the first parameter", m.name))
+			}
+			m.cancellable = true
+			continue
+		}
+		if goType == "*Caller" {
+			if i != 0 {
+				fail(fmt.Errorf("%s: *Caller must be the first parameter", m.name))
+			}
+			m.caller = true
+			continue
+		}
+		if strings.Contains(goType, ".") {
+			fail(fmt.Errorf("%s: parameters of type %s are not supported", m.name, goType))
+		}
+		for _, name := range field.Names {
+			m.params = append(m.params, param{name: name.Name, goType: goType, tsType: ts.typeOf(field.Type)})
+		}
+	}
+	m.returns = "void"
+	if fn.Type.Results != nil {
+		for _, field := range fn.Type.Results.List {
+			m.results = append(m.results, exprString(field.Type))
+			if exprString(field.Type) != "error" {
+				m.returns = ts.typeOf(field.Type)
+			}
+		}
+	}
+	if len(m.results) > 2 {
+		fail(fmt.Errorf("%s: too many return values", m.name))
+	}
+	return m
+}
+
+func writeDispatch(g *group) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen/main.go; DO NOT EDIT.\n\n")
+	if g.constraint != "" {
+		fmt.Fprintf(&buf, "//go:build %s\n\n", g.constraint)
+	}
+	buf.WriteString("package bindings\n\nimport (\n\t\"context\"\n\t\"encoding/json\"\n)\n\nfunc init() {\n")
+	for _, m := range g.methods {
+		fmt.Fprintf(&buf, "\tregister(%q, &binding{\n", m.name)
+		fmt.Fprintf(&buf, "\t\tmanifest: MethodManifest{Name: %q, Params: []ParamManifest{", jsName(m.name))
+		for i, p := range m.params {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "{%q, %q}", p.name, p.tsType)
+		}
+		fmt.Fprintf(&buf, "}, Returns: %q, Cancellable: %v},\n", m.returns, m.cancellable)
+		buf.WriteString("\t\tcall: func(b *Bindings, ctx context.Context, raw []json.RawMessage) (any, error) {\n")
+		args := []string{}
+		if m.cancellable {
+			args = append(args, "ctx")
+		}
+		if m.caller {
+			args = append(args, "callerOf(ctx)")
+		}
+		for i, p := range m.params {
+			fmt.Fprintf(&buf, "\t\t\tvar %s %s\n", p.name, p.goType)
+			fmt.Fprintf(&buf, "\t\t\tif err := decodeArg(raw, %d, %q, &%s); err != nil {\n\t\t\t\treturn nil, err\n\t\t\t}\n", i, p.name, p.name)
+			args = append(args, p.name)
+		}
+		call := fmt.Sprintf("b.%s(%s)", m.name, strings.Join(args, ", "))
+		switch {
+		case len(m.results) == 0:
+			fmt.Fprintf(&buf, "\t\t\t%s\n\t\t\treturn nil, nil\n", call)
+		case len(m.results) == 1 && m.results[0] == "error":
+			fmt.Fprintf(&buf, "\t\t\treturn nil, %s\n", call)
+		case len(m.results) == 1:
+			fmt.Fprintf(&buf, "\t\t\treturn %s, nil\n", call)
+		default:
+			fmt.Fprintf(&buf, "\t\t\treturn %s\n", call)
+		}
+		buf.WriteString("\t\t},\n\t})\n")
+	}
+	buf.WriteString("}\n")
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		fail(fmt.Errorf("%w\n%s", err, buf.String()))
+	}
+	filename := "dispatch_gen.go"
+	if g.constraint != "" {
+		// eg. "!darwin || CI" -> dispatch_not_darwin_or_ci_gen.go
+		replacer := strings.NewReplacer("!", " not ", "||", " or ", "&&", " and ", "(", " ", ")", " ")
+		filename = "dispatch_" + strings.ToLower(strings.Join(strings.Fields(replacer.Replace(g.constraint)), "_")) + "_gen.go"
+	}
+	if err := os.WriteFile(filename, formatted, 0644); err != nil {
+		fail(err)
+	}
+}
+
+func writeDefinitions(methods []method, ts *tsWriter) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen/main.go; DO NOT EDIT.\n\n")
+	buf.WriteString(ts.buf.String())
+	buf.WriteString("export interface SageBindings {\n")
+	for _, m := range methods {
+		params := []string{}
+		for _, p := range m.params {
+			params = append(params, fmt.Sprintf("%s: %s", p.name, p.tsType))
+		}
+		fmt.Fprintf(&buf, "  %s(%s): Promise<%s>;\n", jsName(m.name), strings.Join(params, ", "), m.returns)
+	}
+	buf.WriteString("}\n")
+	if err := os.WriteFile("bindings.d.ts", buf.Bytes(), 0644); err != nil {
+		fail(err)
+	}
+}
+
+type tsWriter struct {
+	structs map[string]*ast.StructType
+	emitted map[string]bool
+	buf     bytes.Buffer
+}
+
+func (ts *tsWriter) typeOf(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return "string"
+		case "bool":
+			return "boolean"
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+			return "number"
+		case "any":
+			return "any"
+		}
+		if st, ok := ts.structs[t.Name]; ok {
+			ts.emitStruct(t.Name, st)
+			return t.Name
+		}
+		return "any"
+	case *ast.StarExpr:
+		return ts.typeOf(t.X) + " | null"
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			// encoding/json turns these into base64
+			return "string"
+		}
+		elem := ts.typeOf(t.Elt)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case *ast.MapType:
+		return fmt.Sprintf("Record<%s, %s>", ts.typeOf(t.Key), ts.typeOf(t.Value))
+	}
+	return "any"
+}
+
+func (ts *tsWriter) emitStruct(name string, st *ast.StructType) {
+	if ts.emitted[name] {
+		return
+	}
+	ts.emitted[name] = true
+
+	var fields bytes.Buffer
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 || !field.Names[0].IsExported() {
+			continue
+		}
+		jsonName := field.Names[0].Name
+		optional := false
+		if field.Tag != nil {
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			options := strings.Split(reflect.StructTag(tag).Get("json"), ",")
+			if options[0] == "-" {
+				continue
+			}
+			if options[0] != "" {
+				jsonName = options[0]
+			}
+			for _, option := range options[1:] {
+				optional = optional || option == "omitempty"
+			}
+		}
+		fieldType := ts.typeOf(field.Type)
+		if optional {
+			jsonName += "?"
+		}
+		fmt.Fprintf(&fields, "  %s: %s;\n", jsonName, fieldType)
+	}
+	fmt.Fprintf(&ts.buf, "export interface %s {\n%s}\n\n", name, fields.String())
+}
+
+func buildConstraint(file *ast.File) string {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if constraint.IsGoBuild(comment.Text) {
+				expr, err := constraint.Parse(comment.Text)
+				if err != nil {
+					fail(err)
+				}
+				return expr.String()
+			}
+		}
+	}
+	return ""
+}
+
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	case *ast.MapType:
+		return "map[" + exprString(t.Key) + "]" + exprString(t.Value)
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.InterfaceType:
+		return "any"
+	}
+	fail(fmt.Errorf("unsupported type %T", expr))
+	return ""
+}
+
+func jsName(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "gen:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,264 @@
+package bindings
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// everything the frontend holds a handle to (HTTP clients, proxies, browsers, the loopback
+// server and blobs) is tracked here, so it can be listed, cleaned up when it hasn't been
+// used for a while, and torn down when the pages that use it are gone
+
+const (
+	handleHttp    = "http"
+	handleProxy   = "proxy"
+	handleBrowser = "browser"
+	handleServer  = "server"
+	handleBlob    = "blob"
+)
+
+// what a handle lives as long as: the main page, which is replaced on every navigation,
+// or the other windows, which keep their pages
+type owner uint8
+
+const (
+	ownerPage owner = 1 << iota
+	ownerWindow
+)
+
+const (
+	handleIdleTimeout = 1 * time.Hour
+	handleGCInterval  = 1 * time.Minute
+	// how long closing a handle may take, unless the caller has its own deadline
+	handleCloseTimeout = 5 * time.Second
+)
+
+type handle struct {
+	id       string
+	kind     string
+	origin   string
+	created  time.Time
+	lastUsed time.Time
+	value    any
+	close    func(ctx context.Context)
+	// handles this one depends on (eg. the proxy of a browser); they are kept while it exists
+	uses []string
+	// shared handles (the loopback server) can have more than one
+	owners owner
+}
+
+type HandleInfo struct {
+	Handle   string    `json:"handle"`
+	Kind     string    `json:"kind"`
+	Origin   string    `json:"origin"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+var handles = map[string]*handle{}
+var handleLock = sync.Mutex{}
+
+func newHandleId() (string, error) {
+	rawHandle := make([]byte, 16)
+	if _, err := rand.Read(rawHandle); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", rawHandle), nil
+}
+
+// caller made the call that creates the handle, it owns the handle
+func (b *Bindings) addHandle(caller *Caller, kind string, id string, value any, close func(ctx context.Context), uses ...string) {
+	now := time.Now()
+	h := &handle{
+		id:       id,
+		kind:     kind,
+		origin:   caller.origin,
+		created:  now,
+		lastUsed: now,
+		value:    value,
+		close:    close,
+		uses:     uses,
+		owners:   caller.owner,
+	}
+	handleLock.Lock()
+	handles[id] = h
+	handleLock.Unlock()
+}
+
+func getHandle[T any](kind string, id string) (T, error) {
+	handleLock.Lock()
+	defer handleLock.Unlock()
+	h, ok := handles[id]
+	if !ok || h.kind != kind {
+		var zero T
+		return zero, withDetails(ErrInvalidHandle, map[string]string{"handle": id, "kind": kind})
+	}
+	h.lastUsed = time.Now()
+	return h.value.(T), nil
+}
+
+// the first handle of a kind, for things there is only one of; the caller becomes one of
+// its owners
+func findHandle(caller *Caller, kind string) (string, bool) {
+	handleLock.Lock()
+	defer handleLock.Unlock()
+	for id, h := range handles {
+		if h.kind == kind {
+			if caller != nil {
+				h.owners |= caller.owner
+			}
+			return id, true
+		}
+	}
+	return "", false
+}
+
+func destroyHandle(kind string, id string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), handleCloseTimeout)
+	defer cancel()
+	return destroyHandles(ctx, func(h *handle) bool {
+		return h.id == id && h.kind == kind
+	}) > 0
+}
+
+// drops the caller from the owners of the handle, and destroys it once nobody owns it
+func releaseHandle(caller *Caller, kind string, id string) bool {
+	handleLock.Lock()
+	h, ok := handles[id]
+	if ok && h.kind == kind {
+		h.owners &^= caller.owner
+		ok = h.owners == 0
+	}
+	handleLock.Unlock()
+	return ok && destroyHandle(kind, id)
+}
+
+func destroyHandles(ctx context.Context, match func(h *handle) bool) int {
+	handleLock.Lock()
+	destroyed := []*handle{}
+	for id, h := range handles {
+		if match(h) {
+			delete(handles, id)
+			destroyed = append(destroyed, h)
+		}
+	}
+	handleLock.Unlock()
+
+	// closing may take a moment (or call back into the registry)
+	for _, h := range destroyed {
+		logger.Debug("Destroying handle", "kind", h.kind, "handle", h.id)
+		if h.close != nil {
+			h.close(ctx)
+		}
+	}
+	return len(destroyed)
+}
+
+func destroyAllHandles(reason string) int {
+	ctx, cancel := context.WithTimeout(context.Background(), handleCloseTimeout)
+	defer cancel()
+	count := destroyHandles(ctx, func(h *handle) bool { return true })
+	if count > 0 {
+		logger.Info("Destroyed all handles", "count", count, "reason", reason)
+	}
+	return count
+}
+
+// the handles of the main page, once it is gone. handles the other windows own as well, or
+// that a handle which is kept uses, stay
+func destroyPageHandles() int {
+	handleLock.Lock()
+	for _, h := range handles {
+		h.owners &^= ownerPage
+	}
+	kept := usedHandles(func(h *handle) bool { return h.owners != 0 })
+	handleLock.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), handleCloseTimeout)
+	defer cancel()
+	count := destroyHandles(ctx, func(h *handle) bool { return h.owners == 0 && !kept[h.id] })
+	if count > 0 {
+		logger.Info("Destroyed the handles of the page", "count", count)
+	}
+	return count
+}
+
+// the handles that match, and everything they use (directly or not); with handleLock held
+func usedHandles(match func(h *handle) bool) map[string]bool {
+	used := map[string]bool{}
+	pending := []string{}
+	for id, h := range handles {
+		if match(h) {
+			pending = append(pending, id)
+		}
+	}
+	for len(pending) > 0 {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if used[id] {
+			continue
+		}
+		used[id] = true
+		if h, ok := handles[id]; ok {
+			pending = append(pending, h.uses...)
+		}
+	}
+	return used
+}
+
+func collectIdleHandles() {
+	handleLock.Lock()
+	used := map[string]bool{}
+	for _, h := range handles {
+		for _, id := range h.uses {
+			used[id] = true
+		}
+	}
+	idle := []string{}
+	for id, h := range handles {
+		// browsers are in use for as long as they run
+		if h.kind != handleBrowser && !used[id] && time.Since(h.lastUsed) > handleIdleTimeout {
+			idle = append(idle, id)
+		}
+	}
+	handleLock.Unlock()
+
+	if len(idle) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), handleCloseTimeout)
+		defer cancel()
+		count := destroyHandles(ctx, func(h *handle) bool { return slices.Contains(idle, h.id) })
+		logger.Info("Collected idle handles", "count", count)
+	}
+}
+
+func runHandleGC() {
+	for range time.Tick(handleGCInterval) {
+		collectIdleHandles()
+	}
+}
+
+func (b *Bindings) HandleList() []HandleInfo {
+	handleLock.Lock()
+	list := []HandleInfo{}
+	for _, h := range handles {
+		list = append(list, HandleInfo{
+			Handle:   h.id,
+			Kind:     h.kind,
+			Origin:   h.origin,
+			Created:  h.created,
+			LastUsed: h.lastUsed,
+		})
+	}
+	handleLock.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Created.Before(list[j].Created) })
+	return list
+}
+
+func (b *Bindings) HandleDestroyAll() int {
+	return destroyAllHandles("request")
+}
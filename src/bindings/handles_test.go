@@ -0,0 +1,67 @@
+package bindings
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sag-enhanced/native-app/src/ui"
+)
+
+func addTestHandle(t *testing.T, caller *Caller, kind string, id string, uses ...string) {
+	(&Bindings{}).addHandle(caller, kind, id, id, nil, uses...)
+	t.Cleanup(func() { destroyHandle(kind, id) })
+}
+
+func alive(kind string, id string) bool {
+	_, err := getHandle[string](kind, id)
+	return err == nil
+}
+
+// a navigation of the main page only takes what nothing else needs anymore
+func TestDestroyPageHandles(t *testing.T) {
+	page := &Caller{origin: "https://app.sage.party", owner: ownerPage}
+	window := &Caller{origin: "https://sage.party", owner: ownerWindow}
+	addTestHandle(t, page, handleHttp, "page client")
+	addTestHandle(t, page, handleProxy, "shared proxy")
+	addTestHandle(t, page, handleProxy, "upstream proxy")
+	addTestHandle(t, window, handleBrowser, "window browser", "shared proxy")
+	addTestHandle(t, page, handleProxy, "chained proxy", "upstream proxy")
+	addTestHandle(t, window, handleHttp, "window client", "chained proxy")
+	addTestHandle(t, page, handleServer, "server")
+	if _, ok := findHandle(window, handleServer); !ok {
+		t.Fatal("the server wasn't found")
+	}
+
+	destroyPageHandles()
+	if alive(handleHttp, "page client") {
+		t.Error("the client of the page survived the navigation")
+	}
+	for _, id := range []string{"shared proxy", "upstream proxy", "chained proxy"} {
+		if !alive(handleProxy, id) {
+			t.Errorf("%s is still used by the window, but was destroyed", id)
+		}
+	}
+	if !alive(handleServer, "server") {
+		t.Error("the server is still used by the window, but was destroyed")
+	}
+
+	(&Bindings{}).ServerDestroy(page)
+	if !alive(handleServer, "server") {
+		t.Error("the page stopped the server of the window")
+	}
+	(&Bindings{}).ServerDestroy(window)
+	if alive(handleServer, "server") {
+		t.Error("the server outlived its last owner")
+	}
+}
+
+func TestCallerOf(t *testing.T) {
+	ctx, c := newCall(ui.WindowCallIds, "HttpClient", "https://sage.party")
+	defer c.done()
+	if caller := callerOf(ctx); caller.origin != "https://sage.party" || caller.owner != ownerWindow {
+		t.Errorf("caller = %+v", caller)
+	}
+	if caller := callerOf(context.Background()); caller.owner != ownerPage {
+		t.Errorf("caller without a call = %+v", caller)
+	}
+}
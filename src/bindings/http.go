@@ -1,25 +1,48 @@
 package bindings
 
 import (
-	"crypto/rand"
+	"bytes"
+	"context"
 	"encoding/base64"
-	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"unicode/utf8"
+
+	"github.com/sag-enhanced/native-app/src/helper"
+)
+
+// redirect policies of clients and requests; any other number is the most redirects
+// that are followed before the request fails
+const (
+	// up to 10, like browsers
+	RedirectsFollow = -1
+	// the redirect itself is the response
+	RedirectsNone = 0
 )
 
-var httpClients = make(map[string]http.Client)
-var httpHandleLock = sync.Mutex{}
+const maxFollowedRedirects = 10
+
+type httpClient struct {
+	*http.Client
+	// see HttpSetRedirects
+	redirects atomic.Int64
+}
+
+// what a request does with redirects, and the ones it followed
+type redirectState struct {
+	limit int
+	chain []HTTPRedirect
+}
 
-func (b *Bindings) HttpClient(proxyUrl *string) (string, error) {
-	rawHandle := make([]byte, 16)
-	if _, err := rand.Read(rawHandle); err != nil {
+type redirectKey struct{}
+
+func (b *Bindings) HttpClient(caller *Caller, proxyUrl *string) (string, error) {
+	handle, err := newHandleId()
+	if err != nil {
 		return "", err
 	}
 	jar, err := cookiejar.New(nil)
@@ -27,72 +50,99 @@ func (b *Bindings) HttpClient(proxyUrl *string) (string, error) {
 		return "", err
 	}
 	var proxy func(*http.Request) (*url.URL, error)
+	uses := []string{}
 	if proxyUrl != nil {
 		parsedProxyUrl, err := url.Parse(*proxyUrl)
 		if err != nil {
 			return "", err
 		}
 		if parsedProxyUrl.Hostname() != "127.0.0.1" {
-			return "", errors.New("Only local proxies are allowed.")
+			return "", ErrLocalProxyOnly
 		}
 		proxy = http.ProxyURL(parsedProxyUrl)
+		uses = append(uses, *proxyUrl)
 	}
 
-	handle := fmt.Sprintf("%x", rawHandle)
-	if b.options.Verbose {
-		fmt.Println("Created new HTTP client with handle", handle)
-	}
-	httpHandleLock.Lock()
-	httpClients[handle] = http.Client{Jar: jar, Transport: &http.Transport{Proxy: proxy}}
-	httpHandleLock.Unlock()
+	logger.Debug("Created new HTTP client", "handle", handle)
+	client := &httpClient{Client: &http.Client{Jar: jar, Transport: &http.Transport{Proxy: proxy}, CheckRedirect: checkRedirect}}
+	client.redirects.Store(RedirectsFollow)
+	b.addHandle(caller, handleHttp, handle, client, func(context.Context) {
+		client.CloseIdleConnections()
+	}, uses...)
 	return handle, nil
 }
 
-func (b *Bindings) HttpRequest(handle string, method string, url string, headers map[string]string, body string) (*HTTPResponse, error) {
-	httpHandleLock.Lock()
-	client, ok := httpClients[handle]
-	httpHandleLock.Unlock()
-	if !ok {
-		return nil, errors.New("invalid handle")
-	}
-	var reader io.Reader
-	if strings.HasPrefix(body, "data:") {
-		reader = base64.NewDecoder(base64.StdEncoding, strings.NewReader(strings.SplitN(body, ",", 2)[1]))
-	} else {
-		reader = strings.NewReader(body)
-	}
-	req, err := http.NewRequest(method, url, reader)
+// sets how many redirects requests of the client follow (RedirectsFollow, RedirectsNone or
+// a number); requests can override it
+func (b *Bindings) HttpSetRedirects(handle string, redirects int) error {
+	client, err := getHandle[*httpClient](handleHttp, handle)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	// Prevent access to certain hosts for security reasons
-	if req.URL.Hostname() == "api.sage.party" || strings.HasSuffix(req.URL.Hostname(), ".leodev.cloud") {
-		return nil, errors.New("This host is not allowed to be accessed.")
+	if redirects < RedirectsFollow {
+		return withMessageAndDetails(ErrInvalidArgument, "redirects must be -1 (follow), 0 (none) or a maximum", map[string]int{"redirects": redirects})
 	}
+	client.redirects.Store(int64(redirects))
+	return nil
+}
 
-	for key, value := range headers {
-		req.Header.Add(key, value)
+// HttpRequest with all values of repeated headers, control over redirects and everything
+// the response has to say; the body is a blob
+func (b *Bindings) HttpFetch(ctx context.Context, handle string, request HTTPFetchRequest) (*HTTPFetchResponse, error) {
+	var data []byte
+	if request.Body != nil {
+		var err error
+		if data, err = request.Body.Bytes(); err != nil {
+			return nil, err
+		}
+	}
+	if request.Redirects != nil && *request.Redirects < RedirectsFollow {
+		return nil, withMessageAndDetails(ErrInvalidArgument, "redirects must be -1 (follow), 0 (none) or a maximum", map[string]int{"redirects": *request.Redirects})
+	}
+	method := request.Method
+	if method == "" {
+		method = http.MethodGet
 	}
 
-	resp, err := client.Do(req)
+	resp, responseBody, chain, err := b.httpDo(ctx, handle, method, request.Url, request.Headers, request.Redirects, data)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
+	blob, err := b.newBlob(callerOf(ctx), responseBody)
 	if err != nil {
 		return nil, err
 	}
+	trailers := resp.Trailer
+	if trailers == nil {
+		trailers = http.Header{}
+	}
+	return &HTTPFetchResponse{
+		StatusCode: resp.StatusCode,
+		Url:        resp.Request.URL.String(),
+		Headers:    resp.Header,
+		Trailers:   trailers,
+		Redirects:  chain,
+		Body:       blob,
+	}, nil
+}
 
-	responseHeaders := map[string]string{}
-	for key, value := range resp.Header {
-		responseHeaders[key] = value[0]
+func (b *Bindings) HttpRequest(ctx context.Context, handle string, method string, url string, headers map[string]string, body string) (*HTTPResponse, error) {
+	data := []byte(body)
+	if strings.HasPrefix(body, "data:") {
+		// decoded up front, the request needs its exact length
+		_, encoded, ok := strings.Cut(body, ",")
+		if !ok {
+			return nil, withMessage(ErrInvalidArgument, "invalid data URL")
+		}
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, withMessage(ErrInvalidArgument, "invalid base64 data")
+		}
 	}
 
-	if b.options.Verbose {
-		fmt.Println("HTTP request", method, url, "returned", resp.StatusCode)
+	resp, responseBody, _, err := b.httpDo(ctx, handle, method, url, expandHeaders(headers), nil, data)
+	if err != nil {
+		return nil, err
 	}
 
 	var stringifiedBody string
@@ -104,17 +154,142 @@ func (b *Bindings) HttpRequest(handle string, method string, url string, headers
 
 	return &HTTPResponse{
 		StatusCode: resp.StatusCode,
-		Headers:    responseHeaders,
+		Headers:    flattenHeaders(resp.Header),
 		Body:       stringifiedBody,
 	}, nil
 }
 
-func (b *Bindings) HttpCookie(handle string, domain string, name string, value *string) (string, error) {
-	httpHandleLock.Lock()
-	client, ok := httpClients[handle]
-	httpHandleLock.Unlock()
+// same as HttpRequest, but the bodies are blobs instead of (maybe base64 encoded) strings
+func (b *Bindings) HttpRequestBlob(ctx context.Context, handle string, method string, url string, headers map[string]string, body Data) (*HTTPBlobResponse, error) {
+	var data []byte
+	if body != (Data{}) {
+		var err error
+		if data, err = body.Bytes(); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, responseBody, _, err := b.httpDo(ctx, handle, method, url, expandHeaders(headers), nil, data)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := b.newBlob(callerOf(ctx), responseBody)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPBlobResponse{
+		StatusCode: resp.StatusCode,
+		Headers:    flattenHeaders(resp.Header),
+		Body:       blob,
+	}, nil
+}
+
+// redirects is nil for the policy of the client; the trailers of the response are only
+// there because the body is read completely
+func (b *Bindings) httpDo(ctx context.Context, handle string, method string, url string, headers map[string][]string, redirects *int, body []byte) (*http.Response, []byte, []HTTPRedirect, error) {
+	client, err := getHandle[*httpClient](handleHttp, handle)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	state := &redirectState{limit: int(client.redirects.Load()), chain: []HTTPRedirect{}}
+	if redirects != nil {
+		state.limit = *redirects
+	}
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, redirectKey{}, state), method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// net/http has taken the length from the plain reader, so the body isn't chunked and
+	// can be sent again on 307/308
+	if len(body) > 0 {
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(helper.NewProgressReader(ctx, bytes.NewReader(body), "upload", int64(len(body)))), nil
+		}
+		req.Body, _ = req.GetBody()
+		req.ContentLength = int64(len(body))
+	}
+	if err := checkHost(req.URL); err != nil {
+		return nil, nil, nil, err
+	}
+
+	for key, values := range headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(helper.NewProgressReader(ctx, resp.Body, "download", resp.ContentLength))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	logger.Debug("HTTP request", "method", method, "url", url, "status", resp.StatusCode, "redirects", len(state.chain))
+	return resp, responseBody, state.chain, nil
+}
+
+// Prevent access to certain hosts for security reasons
+func checkHost(u *url.URL) error {
+	if u.Hostname() == "api.sage.party" || strings.HasSuffix(u.Hostname(), ".leodev.cloud") {
+		return withDetails(ErrForbiddenHost, map[string]string{"host": u.Hostname()})
+	}
+	return nil
+}
+
+// redirects are held to the same hosts as the request itself
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	state, ok := req.Context().Value(redirectKey{}).(*redirectState)
 	if !ok {
-		return "", errors.New("invalid handle")
+		return nil
+	}
+	limit := state.limit
+	switch limit {
+	case RedirectsNone:
+		return http.ErrUseLastResponse
+	case RedirectsFollow:
+		limit = maxFollowedRedirects
+	}
+	if len(via) > limit {
+		return withDetails(ErrTooManyRedirects, map[string]int{"max": limit})
+	}
+	if err := checkHost(req.URL); err != nil {
+		return err
+	}
+	state.chain = append(state.chain, HTTPRedirect{
+		StatusCode: req.Response.StatusCode,
+		Url:        via[len(via)-1].URL.String(),
+		Headers:    req.Response.Header,
+		Location:   req.URL.String(),
+	})
+	return nil
+}
+
+func expandHeaders(headers map[string]string) map[string][]string {
+	expanded := map[string][]string{}
+	for key, value := range headers {
+		expanded[key] = []string{value}
+	}
+	return expanded
+}
+
+func flattenHeaders(header http.Header) map[string]string {
+	flattened := map[string]string{}
+	for key, value := range header {
+		flattened[key] = value[0]
+	}
+	return flattened
+}
+
+func (b *Bindings) HttpCookie(handle string, domain string, name string, value *string) (string, error) {
+	client, err := getHandle[*httpClient](handleHttp, handle)
+	if err != nil {
+		return "", err
 	}
 	if value != nil {
 		client.Jar.SetCookies(&url.URL{Scheme: "https", Host: domain}, []*http.Cookie{
@@ -127,16 +302,11 @@ func (b *Bindings) HttpCookie(handle string, domain string, name string, value *
 			return cookie.Value, nil
 		}
 	}
-	return "", errors.New("cookie not found")
+	return "", withMessage(ErrNotFound, "cookie not found")
 }
 
 func (b *Bindings) HttpDestroy(handle string) {
-	if b.options.Verbose {
-		fmt.Println("Destroying HTTP client with handle", handle)
-	}
-	httpHandleLock.Lock()
-	delete(httpClients, handle)
-	httpHandleLock.Unlock()
+	destroyHandle(handleHttp, handle)
 }
 
 type HTTPResponse struct {
@@ -144,3 +314,38 @@ type HTTPResponse struct {
 	Headers    map[string]string `json:"headers"`
 	Body       string            `json:"body"`
 }
+
+type HTTPBlobResponse struct {
+	StatusCode int               `json:"status"`
+	Headers    map[string]string `json:"headers"`
+	Body       *Blob             `json:"body"`
+}
+
+type HTTPFetchRequest struct {
+	// GET if empty
+	Method  string              `json:"method,omitempty"`
+	Url     string              `json:"url"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    *Data               `json:"body,omitempty"`
+	// RedirectsFollow, RedirectsNone or a maximum; the policy of the client if missing
+	Redirects *int `json:"redirects,omitempty"`
+}
+
+type HTTPRedirect struct {
+	StatusCode int                 `json:"status"`
+	Url        string              `json:"url"`
+	Headers    map[string][]string `json:"headers"`
+	// where it redirected to
+	Location string `json:"location"`
+}
+
+type HTTPFetchResponse struct {
+	StatusCode int `json:"status"`
+	// where the response came from, after all redirects
+	Url      string              `json:"url"`
+	Headers  map[string][]string `json:"headers"`
+	Trailers map[string][]string `json:"trailers"`
+	// the redirects that were followed, in order
+	Redirects []HTTPRedirect `json:"redirects"`
+	Body      *Blob          `json:"body"`
+}
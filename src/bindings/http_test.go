@@ -0,0 +1,78 @@
+package bindings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// /hop/N redirects to /hop/N+1 forever, /away redirects to a host we never talk to
+func redirectServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/away" {
+			http.Redirect(w, r, "https://api.sage.party/", http.StatusFound)
+			return
+		}
+		hop, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hop/"))
+		http.Redirect(w, r, fmt.Sprintf("/hop/%d", hop+1), http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getWithLimit(t *testing.T, url string, limit int) (*http.Response, *redirectState, error) {
+	state := &redirectState{limit: limit}
+	client := &http.Client{CheckRedirect: checkRedirect}
+	req, err := http.NewRequestWithContext(context.WithValue(context.Background(), redirectKey{}, state), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return resp, state, err
+}
+
+func TestRedirectLimit(t *testing.T) {
+	server := redirectServer(t)
+
+	_, state, err := getWithLimit(t, server.URL+"/hop/0", 3)
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("err = %v", err)
+	}
+	// the redirects that were followed are in the chain, the one that wasn't isn't
+	if len(state.chain) != 3 || !strings.HasSuffix(state.chain[2].Location, "/hop/3") {
+		t.Fatalf("chain = %+v", state.chain)
+	}
+
+	_, state, err = getWithLimit(t, server.URL+"/hop/0", RedirectsFollow)
+	if !errors.Is(err, ErrTooManyRedirects) || len(state.chain) != maxFollowedRedirects {
+		t.Fatalf("follow: %v after %d redirects", err, len(state.chain))
+	}
+}
+
+func TestRedirectNone(t *testing.T) {
+	server := redirectServer(t)
+	resp, state, err := getWithLimit(t, server.URL+"/hop/0", RedirectsNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "/hop/1" || len(state.chain) != 0 {
+		t.Fatalf("got %d to %q, chain %+v", resp.StatusCode, resp.Header.Get("Location"), state.chain)
+	}
+}
+
+// the forbidden hosts are checked before a redirect to them is followed, not only up front
+func TestRedirectForbiddenHost(t *testing.T) {
+	server := redirectServer(t)
+	_, state, err := getWithLimit(t, server.URL+"/away", RedirectsFollow)
+	if !errors.Is(err, ErrForbiddenHost) || len(state.chain) != 0 {
+		t.Fatalf("err = %v, chain = %+v", err, state.chain)
+	}
+}
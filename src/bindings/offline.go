@@ -0,0 +1,16 @@
+package bindings
+
+import "github.com/sag-enhanced/native-app/src/bundle"
+
+type OfflineStatus struct {
+	Offline bool   `json:"offline"`
+	Reason  string `json:"reason,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// whether the frontend is served from a bundle instead of the realm (also signalled with a
+// sageo event on every page load while it is)
+func (b *Bindings) Offline() OfflineStatus {
+	status := bundle.CurrentStatus()
+	return OfflineStatus{Offline: status.Offline, Reason: status.Reason, Version: status.Version}
+}
@@ -30,6 +30,19 @@ func (b *Bindings) Save2(filename string, data string) error {
 	return os.WriteFile(path, decoded, 0644)
 }
 
+func (b *Bindings) SaveBlob(filename string, data Data) error {
+	content, err := data.Bytes()
+	if err != nil {
+		return err
+	}
+	path, err := dialog.File().Title("Save file").SetStartFile(filename).Filter("All files", "*").Save()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
+
 func (b *Bindings) Read(filterText string, filter string) (string, error) {
 	path, err := dialog.File().Title("Open file").Filter(filterText, filter).Load()
 	if err != nil {
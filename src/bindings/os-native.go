@@ -1,7 +1,6 @@
 package bindings
 
 import (
-	"fmt"
 	"net/url"
 	"strings"
 
@@ -11,14 +10,14 @@ import (
 func (b *Bindings) Open(target string) error {
 	// some sanization checks
 	if strings.ContainsAny(target, "\n\r'\"` {}$|;") {
-		return fmt.Errorf("Invalid URL")
+		return withMessage(ErrInvalidArgument, "Invalid URL")
 	}
 	url, err := url.Parse(target)
 	// only allow https urls and block any path traversal attempts
 	if err != nil || url.Scheme != "https" || strings.Contains(url.Path, "..") {
-		return fmt.Errorf("Invalid URL")
+		return withMessage(ErrInvalidArgument, "Invalid URL")
 	}
-	fmt.Println("Opening URL", url.String())
+	logger.Info("Opening URL", "url", url.String())
 	// re-assemble url to string to avoid any funny business
 	helper.Open(url.String(), b.options)
 	return nil
@@ -3,23 +3,24 @@
 package bindings
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/kbinani/screenshot"
 	"github.com/makiuchi-d/gozxing"
 	"github.com/makiuchi-d/gozxing/multi/qrcode"
 )
 
-func (b *Bindings) ScreenshotQR() ([]string, error) {
+func (b *Bindings) ScreenshotQR(ctx context.Context) ([]string, error) {
 	codes := []string{}
 
 	screens := screenshot.NumActiveDisplays()
 	reader := qrcode.NewQRCodeMultiReader()
 	for i := 0; i < screens; i++ {
-		bounds := screenshot.GetDisplayBounds(i)
-		if b.options.Verbose {
-			fmt.Println("Capturing screen", i, bounds)
+		if err := ctx.Err(); err != nil {
+			return codes, err
 		}
+		bounds := screenshot.GetDisplayBounds(i)
+		logger.Debug("Capturing screen", "screen", i, "bounds", bounds)
 		img, err := screenshot.CaptureRect(bounds)
 		if err != nil {
 			return codes, err
@@ -33,9 +34,7 @@ func (b *Bindings) ScreenshotQR() ([]string, error) {
 		result, err := reader.DecodeMultiple(bmp, nil)
 		if err == nil {
 			for _, result := range result {
-				if b.options.Verbose {
-					fmt.Println("QR Code found on screen", i, result.GetResultMetadata(), result.GetResultPoints())
-				}
+				logger.Debug("QR Code found on screen", "screen", i, "metadata", result.GetResultMetadata(), "points", result.GetResultPoints())
 				codes = append(codes, result.GetText())
 			}
 		}
@@ -0,0 +1,103 @@
+package bindings
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/sag-enhanced/native-app/src/file"
+)
+
+// who may call which binding, checked by BindHandler before anything is decoded
+
+type originSet int
+
+const (
+	originRealm originSet = 1 << iota
+	originIdentity
+	// for calls the init scripts make on every page, before we even know where we are
+	originAny
+)
+
+type policy struct {
+	origins originSet
+	// the encrypted storage has to be unlocked (if encryption is enabled)
+	unlocked bool
+}
+
+// everything that isn't listed here may only be called by the realm
+var defaultPolicy = policy{origins: originRealm}
+
+var policies = map[string]policy{
+	"SetUrl": {origins: originAny},
+
+	// harmless and needed by the identity page as well
+	"Build":            {origins: originRealm | originIdentity},
+	"Start":            {origins: originRealm | originIdentity},
+	"Info":             {origins: originRealm | originIdentity},
+	"Cancel":           {origins: originRealm | originIdentity},
+	"Describe":         {origins: originRealm | originIdentity},
+	"EncryptionStatus": {origins: originRealm | originIdentity},
+	"EncryptionUnlock": {origins: originRealm | originIdentity},
+
+	// connect intents start on the realm and are approved on the identity page
+	"InitConnect":    {origins: originRealm, unlocked: true},
+	"ApproveConnect": {origins: originIdentity, unlocked: true},
+	"RecoverConnect": {origins: originIdentity, unlocked: true},
+
+	"Get":    {origins: originRealm, unlocked: true},
+	"Set":    {origins: originRealm, unlocked: true},
+	"Id":     {origins: originRealm, unlocked: true},
+	"Sign2":  {origins: originRealm, unlocked: true},
+	"Seal":   {origins: originRealm, unlocked: true},
+	"Unseal": {origins: originRealm, unlocked: true},
+
+	"SealBlob":   {origins: originRealm, unlocked: true},
+	"UnsealBlob": {origins: originRealm, unlocked: true},
+}
+
+func getPolicy(methodName string) policy {
+	if p, ok := policies[methodName]; ok {
+		return p
+	}
+	return defaultPolicy
+}
+
+// the webview can't tell us who is calling, so we fall back to what the page reported via setUrl
+func (b *Bindings) callerOrigin(origin string) string {
+	if origin != "" {
+		return origin
+	}
+	current := currentUrl.Load()
+	if current == nil {
+		return ""
+	}
+	return originOf(current)
+}
+
+func (b *Bindings) checkPolicy(methodName string, origin string) error {
+	p := getPolicy(methodName)
+
+	allowed := p.origins&originAny != 0
+	if p.origins&originRealm != 0 && origin == b.options.GetRealmOrigin() {
+		allowed = true
+	}
+	if p.origins&originIdentity != 0 && origin == b.options.GetIdentityOrigin() {
+		allowed = true
+	}
+	if !allowed {
+		return withMessageAndDetails(ErrForbidden,
+			fmt.Sprintf("%s is not allowed to be called from %q", methodName, origin),
+			map[string]string{"method": methodName, "origin": origin})
+	}
+
+	if p.unlocked && b.fm.Manifest != nil && b.fm.Cipher == nil {
+		return withMessageAndDetails(file.ErrLocked,
+			fmt.Sprintf("%s requires the encrypted storage to be unlocked", methodName),
+			map[string]string{"method": methodName})
+	}
+	return nil
+}
+
+func originOf(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
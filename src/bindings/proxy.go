@@ -5,14 +5,13 @@ import (
 	"fmt"
 	"net"
 	"net/url"
-	"sync"
 	"time"
 
 	"github.com/sag-enhanced/native-app/src/options"
 	_ "github.com/wzshiming/anyproxy/proxies/socks5"
 	"github.com/wzshiming/bridge/chain"
 	"github.com/wzshiming/bridge/config"
-	"github.com/wzshiming/bridge/logger"
+	bridgelogger "github.com/wzshiming/bridge/logger"
 	_ "github.com/wzshiming/bridge/protocols/connect"
 	_ "github.com/wzshiming/bridge/protocols/socks4"
 	_ "github.com/wzshiming/bridge/protocols/socks5"
@@ -20,10 +19,7 @@ import (
 	_ "github.com/wzshiming/bridge/protocols/tls"
 )
 
-var proxyClients = make(map[string]context.CancelFunc)
-var proxyHandleLock = sync.Mutex{}
-
-func (b *Bindings) ProxyNew(proxyUrl string) (string, error) {
+func (b *Bindings) ProxyNew(caller *Caller, proxyUrl string) (string, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	parsedProxyUrl, err := url.Parse(proxyUrl)
@@ -37,25 +33,19 @@ func (b *Bindings) ProxyNew(proxyUrl string) (string, error) {
 		cancel()
 		return "", err
 	}
-	if b.options.Verbose {
-		fmt.Println("Created new proxy with handle", proxyUrl, localProxy)
-	}
-
-	proxyHandleLock.Lock()
-	proxyClients[localProxy.String()] = cancel
-	proxyHandleLock.Unlock()
+	logger.Debug("Created new proxy", "handle", localProxy, "upstream", parsedProxyUrl.Redacted())
 
+	// the local proxy URL doubles as the handle, so it can be passed to browsers and clients as is
+	b.addHandle(caller, handleProxy, localProxy.String(), localProxy, func(context.Context) {
+		cancel()
+	})
 	return localProxy.String(), nil
 }
 
 func (b *Bindings) ProxyDestroy(handle string) error {
-	proxyHandleLock.Lock()
-	cancel, ok := proxyClients[handle]
-	proxyHandleLock.Unlock()
-	if !ok {
-		return fmt.Errorf("invalid handle %s", handle)
+	if !destroyHandle(handleProxy, handle) {
+		return withDetails(ErrInvalidHandle, map[string]string{"handle": handle, "kind": handleProxy})
 	}
-	cancel()
 	return nil
 }
 
@@ -69,9 +59,7 @@ func createProxyProxy(proxy *url.URL, options *options.Options, stop context.Con
 		Scheme: "socks5",
 		Host:   fmt.Sprintf("127.0.0.1:%d", freePort),
 	}
-	if options.Verbose {
-		fmt.Println("Local proxy", localProxy)
-	}
+	logger.Debug("Local proxy", "url", localProxy)
 
 	cfg := config.Chain{
 		Bind: []config.Node{
@@ -89,11 +77,11 @@ func createProxyProxy(proxy *url.URL, options *options.Options, stop context.Con
 		},
 		IdleTimeout: 120 * time.Second,
 	}
-	b := chain.NewBridge(logger.Std, false)
+	b := chain.NewBridge(bridgelogger.Std, false)
 
 	go func() {
 		if err := b.BridgeWithConfig(stop, cfg); err != nil {
-			fmt.Println("Error running proxy", err)
+			logger.Error("Error running proxy", "error", err)
 		}
 	}()
 
@@ -5,25 +5,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
-var server *http.Server
+// how long the page has to answer a request with ServerRespond
+const serverTimeout = 10 * time.Second
+
 var serverRequests = make(map[int]chan serverResponse)
 var serverRequestLock = sync.Mutex{}
 
-func (b *Bindings) ServerNew() string {
+// there is one server, shared by every window that asks for it
+func (b *Bindings) ServerNew(caller *Caller) (string, error) {
 	addr := fmt.Sprintf("127.0.0.1:%d", b.options.LoopbackPort)
-	if server != nil {
-		return addr
+	if _, ok := findHandle(caller, handleServer); ok {
+		return addr, nil
+	}
+	// listening right away, so a port that is taken (eg. by another instance) is reported
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
 	}
 
 	requestId := 0
-	server = &http.Server{
+	server := &http.Server{
 		Addr: addr,
 		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// keeps the server from being collected while it is in use
+			getHandle[*http.Server](handleServer, addr)
+
+			// blobs are served natively, everything else is up to the frontend
+			if strings.HasPrefix(r.URL.Path, blobPath) {
+				b.serveBlob(w, r)
+				return
+			}
+
 			body, err := io.ReadAll(r.Body)
 			defer r.Body.Close()
 			if err != nil {
@@ -45,9 +65,7 @@ func (b *Bindings) ServerNew() string {
 				request.Headers[key] = value[0]
 			}
 
-			if b.options.Verbose {
-				fmt.Println("received HTTP request", request.Method, request.Url)
-			}
+			logger.Debug("Received HTTP request", "method", request.Method, "url", request.Url)
 
 			encoded, err := json.Marshal(request)
 			if err != nil {
@@ -56,13 +74,14 @@ func (b *Bindings) ServerNew() string {
 			}
 			sequence := time.Now().UnixNano()
 
-			responseChannel := make(chan serverResponse)
+			// a response that comes in just as the request times out must not block
+			responseChannel := make(chan serverResponse, 1)
 
 			serverRequestLock.Lock()
 			serverRequests[request.Id] = responseChannel
 			serverRequestLock.Unlock()
 
-			b.ui.Eval(fmt.Sprintf("sages(%s, %d)", encoded, sequence))
+			b.ui.Emit("sages", json.RawMessage(encoded), sequence)
 
 			select {
 			case response := <-responseChannel:
@@ -71,24 +90,23 @@ func (b *Bindings) ServerNew() string {
 				}
 				w.WriteHeader(response.StatusCode)
 				w.Write([]byte(response.Body))
-				if b.options.Verbose {
-					fmt.Println("HTTP request", request.Method, request.Url, "returned", response.StatusCode)
-				}
-			case <-time.After(10 * time.Second):
+				logger.Debug("HTTP request answered", "method", request.Method, "url", request.Url, "status", response.StatusCode)
+			case <-time.After(serverTimeout):
 				w.WriteHeader(502)
-				if b.options.Verbose {
-					fmt.Println("HTTP request timed out")
-				}
+				logger.Warn("HTTP request timed out, the page didn't respond", "method", request.Method, "url", request.Url, "request", request.Id, "loopback", pendingServerRequests(), "calls", pendingCalls())
 			}
 
 			serverRequestLock.Lock()
-			delete(serverRequests, requestId)
+			delete(serverRequests, request.Id)
 			serverRequestLock.Unlock()
 		}),
 	}
 
-	go server.ListenAndServe()
-	return addr
+	b.addHandle(caller, handleServer, addr, server, func(ctx context.Context) {
+		server.Shutdown(ctx)
+	})
+	go server.Serve(listener)
+	return addr, nil
 }
 
 func (b *Bindings) ServerRespond(requestId int, statusCode int, headers map[string]string, body string) error {
@@ -100,7 +118,7 @@ func (b *Bindings) ServerRespond(requestId int, statusCode int, headers map[stri
 	serverRequestLock.Unlock()
 
 	if !ok {
-		return fmt.Errorf("invalid request id")
+		return withDetails(ErrInvalidHandle, map[string]int{"request": requestId})
 	}
 
 	response := serverResponse{
@@ -113,10 +131,22 @@ func (b *Bindings) ServerRespond(requestId int, statusCode int, headers map[stri
 	return nil
 }
 
-func (b *Bindings) ServerDestroy() {
-	if server != nil {
-		server.Shutdown(context.Background())
-		server = nil
+// ids of the requests the page hasn't responded to yet
+func pendingServerRequests() []int {
+	serverRequestLock.Lock()
+	defer serverRequestLock.Unlock()
+	pending := []int{}
+	for id := range serverRequests {
+		pending = append(pending, id)
+	}
+	sort.Ints(pending)
+	return pending
+}
+
+// the server keeps running while the other windows use it
+func (b *Bindings) ServerDestroy(caller *Caller) {
+	if addr, ok := findHandle(nil, handleServer); ok {
+		releaseHandle(caller, handleServer, addr)
 	}
 }
 
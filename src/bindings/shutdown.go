@@ -0,0 +1,85 @@
+package bindings
+
+import (
+	"context"
+	"time"
+)
+
+// once the UI is gone (window closed, Quit or a signal), everything the app started
+// is torn down in order, so no browser, proxy or half-written file is left behind
+
+// calls get a part of the deadline, so there is time left for the rest
+const shutdownCallTimeout = 3 * time.Second
+
+type shutdownStep struct {
+	name  string
+	match func(h *handle) bool
+}
+
+// browsers go first, they may still be using the proxies
+var shutdownSteps = []shutdownStep{
+	{"browsers", func(h *handle) bool { return h.kind == handleBrowser }},
+	{"proxies", func(h *handle) bool { return h.kind == handleProxy }},
+	{"server", func(h *handle) bool { return h.kind == handleServer }},
+	{"other", func(h *handle) bool { return true }},
+}
+
+func (b *Bindings) Shutdown(ctx context.Context) {
+	start := time.Now()
+	b.closing.Store(true)
+
+	report := []any{}
+	callLock.Lock()
+	if len(runningCalls) > 0 {
+		report = append(report, "calls", len(runningCalls))
+	}
+	callLock.Unlock()
+
+	// calls still running have nobody to report to anymore
+	cancelCallsBefore(context.Background(), errShuttingDown)
+	callCtx, cancel := context.WithTimeout(ctx, shutdownCallTimeout)
+	defer cancel()
+	if running := waitCalls(callCtx); len(running) > 0 {
+		logger.Warn("RPC calls still running at shutdown", "methods", running)
+	}
+
+	for _, step := range shutdownSteps {
+		if count := destroyHandles(ctx, step.match); count > 0 {
+			report = append(report, step.name, count)
+		}
+	}
+
+	// the calls are done by now (or never will be), so this only waits for a write in progress
+	b.fm.Close()
+	if b.options.LockOnExit {
+		b.fm.Lock()
+	}
+
+	if err := ctx.Err(); err != nil {
+		logger.Warn("Shutdown did not finish in time", "error", err)
+	}
+	logger.Info("Shutdown complete", append(report, "duration", time.Since(start))...)
+}
+
+// waits until no call is running anymore and returns the ones that didn't finish in time
+func waitCalls(ctx context.Context) []string {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		callLock.Lock()
+		running := []string{}
+		for c := range runningCalls {
+			running = append(running, c.method)
+		}
+		callLock.Unlock()
+
+		if len(running) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return running
+		case <-ticker.C:
+		}
+	}
+}
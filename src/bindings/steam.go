@@ -1,11 +1,12 @@
 package bindings
 
 import (
-	"fmt"
+	"context"
 	"os"
 	"path"
 	"strings"
 
+	"github.com/sag-enhanced/native-app/src/helper"
 	"github.com/sag-enhanced/native-app/src/steam"
 )
 
@@ -15,17 +16,13 @@ func (b *Bindings) SteamPatch(js string) error {
 		return err
 	}
 
-	if b.options.Verbose {
-		fmt.Println("Steam executable found at", exe)
-	}
+	logger.Debug("Steam executable found", "path", exe)
 
 	data, err := steam.FindSteamDataDir(b.options)
 	if err != nil {
 		return err
 	}
-	if b.options.Verbose {
-		fmt.Println("Steam data directory found at", data)
-	}
+	logger.Debug("Steam data directory found", "path", data)
 
 	entryFile := path.Join(data, "steamui", "library.js")
 	content, err := os.ReadFile(entryFile)
@@ -45,12 +42,13 @@ func (b *Bindings) SteamPatch(js string) error {
 	return os.WriteFile(entryFile, []byte(strings.Join(lines, "\n")), 0644)
 }
 
-func (b *Bindings) SteamRun() error {
-	steam.CloseSteam(b.options)
-
-	if b.options.Verbose {
-		fmt.Println("Starting Steam with injected code...")
+func (b *Bindings) SteamRun(ctx context.Context) error {
+	if err := steam.CloseSteam(ctx, b.options); err != nil {
+		return err
 	}
+
+	logger.Debug("Starting Steam with injected code")
+	helper.ReportPhase(ctx, "steam-start")
 	// -noverifyfiles is required to prevent steam from checking the files
 	// and redownloading them if they are modified
 	return steam.RunSteamWithArguments(b.options, "-noverifyfiles")
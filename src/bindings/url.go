@@ -1,22 +1,39 @@
 package bindings
 
-import "net/url"
+import (
+	"context"
+	"net/url"
+	"sync/atomic"
+)
 
-var currentUrl *url.URL
+// set from the page, read by every call that needs to know its origin
+var currentUrl atomic.Pointer[url.URL]
 
 // webview has no builtin way to get the current url, so we neded a tamper proof way to access it
 // we use a secret that is only known to the app and the bindings
 // this way we cant be tricked into setting the url to something else
 //
 // we need the current URL to properly restrict certain bindings to only work on certain pages
-func (b *Bindings) SetUrl(currentPageUrl string, secret string) error {
+func (b *Bindings) SetUrl(ctx context.Context, currentPageUrl string, secret string) error {
 	// someone is doing something fishy
 	if b.options.CurrentUrlSecret != secret {
 		b.ui.Quit()
 		return nil
 	}
 
-	var err error
-	currentUrl, err = url.Parse(currentPageUrl)
-	return err
+	// a new page was loaded, nobody is waiting for the calls of the old one anymore
+	cancelCallsBefore(ctx, errNavigated)
+	// and nobody is going to use the handles it created either (the other windows still use theirs)
+	destroyPageHandles()
+
+	parsed, err := url.Parse(currentPageUrl)
+	currentUrl.Store(parsed)
+	if err != nil {
+		return err
+	}
+	// so the page can show that it is running from the bundle
+	if status := b.Offline(); status.Offline {
+		b.ui.Emit("sageo", status)
+	}
+	return nil
 }
@@ -0,0 +1,106 @@
+package bindings
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/sag-enhanced/native-app/src/ui"
+)
+
+type WindowOptions struct {
+	Title string `json:"title"`
+	// only used the first time the window is opened, afterwards it gets the size it had last
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// "" and "main" are the window the app started with
+func (b *Bindings) windows() (ui.Windows, error) {
+	windows, ok := b.ui.(ui.Windows)
+	if !ok {
+		return nil, withMessage(errors.ErrUnsupported, "this UI has no windows")
+	}
+	return windows, nil
+}
+
+// opens another window on a page of the realm (path is like "/logs"), which can call
+// the same bindings as the main window
+func (b *Bindings) WindowOpen(name string, path string, options WindowOptions) error {
+	windows, err := b.windows()
+	if err != nil {
+		return err
+	}
+	if name == "" || name == ui.MainWindow {
+		return withMessageAndDetails(ErrInvalidArgument, "the window needs a name of its own", map[string]string{"name": name})
+	}
+	origin := b.options.GetRealmOrigin()
+	target, err := url.Parse(origin + path)
+	if err != nil || !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || originOf(target) != origin {
+		return withMessageAndDetails(ErrInvalidArgument, "path must be a path in the realm", map[string]string{"path": path})
+	}
+	return windows.OpenWindow(name, target.String(), ui.WindowOptions{Title: options.Title, Width: options.Width, Height: options.Height})
+}
+
+// closing the main window quits the app
+func (b *Bindings) WindowClose(name string) error {
+	windows, err := b.windows()
+	if err != nil {
+		return err
+	}
+	return windows.CloseWindow(name)
+}
+
+func (b *Bindings) WindowList() ([]string, error) {
+	windows, err := b.windows()
+	if err != nil {
+		return nil, err
+	}
+	return windows.ListWindows(), nil
+}
+
+func (b *Bindings) WindowSetTitle(name string, title string) error {
+	windows, err := b.windows()
+	if err != nil {
+		return err
+	}
+	return windows.SetWindowTitle(name, title)
+}
+
+func (b *Bindings) WindowSetSize(name string, width int, height int) error {
+	windows, err := b.windows()
+	if err != nil {
+		return err
+	}
+	if width < ui.MinWindowSize || height < ui.MinWindowSize || width > ui.MaxWindowSize || height > ui.MaxWindowSize {
+		return withMessageAndDetails(ErrInvalidArgument,
+			fmt.Sprintf("size must be between %d and %d", ui.MinWindowSize, ui.MaxWindowSize),
+			map[string]int{"width": width, "height": height})
+	}
+	return windows.SetWindowSize(name, width, height)
+}
+
+func (b *Bindings) WindowSetAlwaysOnTop(name string, enabled bool) error {
+	windows, err := b.windows()
+	if err != nil {
+		return err
+	}
+	return windows.SetWindowAlwaysOnTop(name, enabled)
+}
+
+func (b *Bindings) WindowMinimize(name string) error {
+	windows, err := b.windows()
+	if err != nil {
+		return err
+	}
+	return windows.MinimizeWindow(name)
+}
+
+func (b *Bindings) WindowRestore(name string) error {
+	windows, err := b.windows()
+	if err != nil {
+		return err
+	}
+	return windows.RestoreWindow(name)
+}
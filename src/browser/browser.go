@@ -4,14 +4,19 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"os"
 	"path"
 
+	"github.com/sag-enhanced/native-app/src/helper"
+	"github.com/sag-enhanced/native-app/src/logging"
 	"github.com/sag-enhanced/native-app/src/options"
 )
 
-func RunBrowser(stop context.Context, options *options.Options, browserUrl string, browser string, proxy *url.URL, profileId int32) error {
-	var err error
+var logger = logging.For("browser")
 
+// starts the browser process; the context only covers the launch itself,
+// the browser keeps running until WaitBrowser is told to stop it
+func LaunchBrowser(ctx context.Context, options *options.Options, browserUrl string, browser string, proxy *url.URL, profileId int32) (*os.Process, error) {
 	profile := path.Join(options.DataDirectory, "profiles", browser, fmt.Sprint(profileId))
 
 	args := prepareArguments(profile, proxy)
@@ -23,9 +28,9 @@ func RunBrowser(stop context.Context, options *options.Options, browserUrl strin
 	exe := options.ForceBrowser
 	if exe == "" {
 		var err error
-		exe, err = findBrowserBinary(browser)
+		exe, err = findBrowserBinary(ctx, browser)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -33,14 +38,18 @@ func RunBrowser(stop context.Context, options *options.Options, browserUrl strin
 		args = append(args, "--proxy-bypass-list="+options.ProxyBypassList)
 	}
 
-	if options.Verbose {
-		fmt.Println("Running browser with args", exe, args)
+	// finding the binary may have installed chromium, which takes a while
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	proc, err := launchBrowser(exe, args)
-	if err != nil {
-		return err
-	}
+	logger.Debug("Running browser", "executable", exe, "args", args)
+	helper.ReportPhase(ctx, "browser-launch")
+
+	return launchBrowser(exe, args)
+}
+
+func WaitBrowser(stop context.Context, proc *os.Process) {
 	defer proc.Kill()
 
 	processDone := make(chan struct{})
@@ -53,6 +62,4 @@ func RunBrowser(stop context.Context, options *options.Options, browserUrl strin
 	case <-stop.Done():
 	case <-processDone:
 	}
-
-	return nil
 }
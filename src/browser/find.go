@@ -1,24 +1,33 @@
 package browser
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"os"
 	"path"
 	"runtime"
 
 	"github.com/playwright-community/playwright-go"
+	"github.com/sag-enhanced/native-app/src/helper"
+	"github.com/sag-enhanced/native-app/src/logging"
 )
 
-func findBrowserBinary(browser string) (string, error) {
+var ErrBrowserNotFound = errors.New("Browser binary not found")
+
+func findBrowserBinary(ctx context.Context, browser string) (string, error) {
 	if browser == "chromium" {
 		// we use playwright to manage our chromium installation
-
-		playwright.Install(&playwright.RunOptions{
+		helper.ReportPhase(ctx, "browser-install")
+		runOptions := &playwright.RunOptions{
 			Browsers: []string{browser},
 			Verbose:  true,
-		})
+			Stdout:   logging.Stream(logger, "stdout"),
+			Stderr:   logging.Stream(logger, "stderr"),
+			Logger:   logger,
+		}
+		playwright.Install(runOptions)
 
-		pw, err := playwright.Run()
+		pw, err := playwright.Run(runOptions)
 		if err != nil {
 			return "", err
 		}
@@ -26,7 +35,11 @@ func findBrowserBinary(browser string) (string, error) {
 
 		return pw.Chromium.ExecutablePath(), nil
 	}
+	return FindInstalledBrowser(browser)
+}
 
+// chrome or edge, where the installer puts them
+func FindInstalledBrowser(browser string) (string, error) {
 	switch runtime.GOOS {
 	case "darwin":
 		name := "Google Chrome"
@@ -65,5 +78,5 @@ func findBrowserBinary(browser string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("Browser binary not found")
+	return "", ErrBrowserNotFound
 }
@@ -5,8 +5,11 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
+
+	"github.com/sag-enhanced/native-app/src/logging"
 )
 
 func prepareArguments(profile string, proxy *url.URL) []string {
@@ -36,8 +39,9 @@ func prepareExtensions(args []string, extensions []string) []string {
 
 func launchBrowser(exe string, args []string) (*os.Process, error) {
 	cmd := exec.Command(exe, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	output := logger.With("executable", filepath.Base(exe))
+	cmd.Stdout = logging.Stream(output, "stdout")
+	cmd.Stderr = logging.Stream(output, "stderr")
 	err := cmd.Start()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,226 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// a bundle is the frontend as a zip (or directory) with a manifest.json listing the
+// sha256 of every file, and manifest.sig, an ed25519 signature of manifest.json made
+// with the key below. nothing in a bundle is served unless all of it checks out
+
+// base64 ed25519 public key, set at build time with
+// -ldflags "-X github.com/sag-enhanced/native-app/src/bundle.publicKey=..."
+// without it, bundles are not used at all
+var publicKey string
+
+const (
+	manifestFile  = "manifest.json"
+	signatureFile = "manifest.sig"
+	indexFile     = "index.html"
+	// uncompressed, all files together
+	maxBundleSize = 100 * 1024 * 1024
+)
+
+var (
+	ErrNoKey         = errors.New("this build has no bundle key, so bundles can't be verified")
+	ErrInvalidBundle = errors.New("invalid bundle")
+)
+
+type Manifest struct {
+	Version string `json:"version"`
+	// the realm the bundle belongs to
+	Origin string `json:"origin"`
+	// path -> hex sha256
+	Files map[string]string `json:"files"`
+}
+
+type Bundle struct {
+	Manifest Manifest
+	files    map[string][]byte
+}
+
+func Enabled() bool {
+	return publicKey != ""
+}
+
+// loads and verifies a zip file or a directory
+func Load(filename string) (*Bundle, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		return Parse(content)
+	}
+
+	files := map[string][]byte{}
+	size := 0
+	err = fs.WalkDir(os.DirFS(filename), ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(filepath.Join(filename, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		if size += len(content); size > maxBundleSize {
+			return fmt.Errorf("%w: larger than %d bytes", ErrInvalidBundle, maxBundleSize)
+		}
+		files[name] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return verify(files)
+}
+
+// verifies a zip file in memory
+func Parse(content []byte) (*Bundle, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	files := map[string][]byte{}
+	remaining := int64(maxBundleSize)
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		if !fs.ValidPath(entry.Name) {
+			return nil, fmt.Errorf("%w: bad path %q", ErrInvalidBundle, entry.Name)
+		}
+		reader, err := entry.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+		}
+		// the sizes in the zip header can't be trusted
+		data, err := io.ReadAll(io.LimitReader(reader, remaining+1))
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+		}
+		if remaining -= int64(len(data)); remaining < 0 {
+			return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidBundle, maxBundleSize)
+		}
+		files[entry.Name] = data
+	}
+	return verify(files)
+}
+
+func verify(files map[string][]byte) (*Bundle, error) {
+	if !Enabled() {
+		return nil, ErrNoKey
+	}
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: the compiled in key is broken", ErrNoKey)
+	}
+
+	manifestContent, ok := files[manifestFile]
+	if !ok {
+		return nil, fmt.Errorf("%w: no %s", ErrInvalidBundle, manifestFile)
+	}
+	signature, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(files[signatureFile])))
+	if err != nil || !ed25519.Verify(key, manifestContent, signature) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidBundle)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestContent, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	if _, ok := manifest.Files[indexFile]; !ok {
+		return nil, fmt.Errorf("%w: no %s", ErrInvalidBundle, indexFile)
+	}
+	for name, digest := range manifest.Files {
+		content, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidBundle, name)
+		}
+		actual := sha256.Sum256(content)
+		if hex.EncodeToString(actual[:]) != digest {
+			return nil, fmt.Errorf("%w: %s was modified", ErrInvalidBundle, name)
+		}
+	}
+	for name := range files {
+		if _, ok := manifest.Files[name]; !ok && name != manifestFile && name != signatureFile {
+			return nil, fmt.Errorf("%w: %s is not in the manifest", ErrInvalidBundle, name)
+		}
+	}
+
+	delete(files, manifestFile)
+	delete(files, signatureFile)
+	return &Bundle{Manifest: manifest, files: files}, nil
+}
+
+// builds a signed bundle (as a zip) from the files of a directory
+func Sign(directory string, origin string, version string, key ed25519.PrivateKey) ([]byte, error) {
+	manifest := Manifest{Version: version, Origin: origin, Files: map[string]string{}}
+	files := map[string][]byte{}
+	err := fs.WalkDir(os.DirFS(directory), ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || name == manifestFile || name == signatureFile {
+			return err
+		}
+		content, err := os.ReadFile(filepath.Join(directory, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		digest := sha256.Sum256(content)
+		manifest.Files[name] = hex.EncodeToString(digest[:])
+		files[name] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := files[indexFile]; !ok {
+		return nil, fmt.Errorf("%w: no %s", ErrInvalidBundle, indexFile)
+	}
+
+	manifestContent, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[manifestFile] = manifestContent
+	files[signatureFile] = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, manifestContent)))
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, name := range names {
+		writer, err := archive.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
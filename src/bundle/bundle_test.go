@@ -0,0 +1,186 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// the key the tests sign with is the compiled in one for the duration of the test
+func useKey(t *testing.T) ed25519.PrivateKey {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := publicKey
+	publicKey = base64.StdEncoding.EncodeToString(public)
+	t.Cleanup(func() { publicKey = previous })
+	return private
+}
+
+func signDirectory(t *testing.T, key ed25519.PrivateKey, files map[string]string) []byte {
+	directory := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(directory, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	content, err := Sign(directory, "https://app.example.com", "1.2.3", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+// copies a signed zip, letting change edit the files on the way
+func rezip(t *testing.T, content []byte, change func(files map[string][]byte)) []byte {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, entry := range archive.File {
+		reader, err := entry.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[entry.Name], _ = io.ReadAll(reader)
+		reader.Close()
+	}
+	change(files)
+
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for name, data := range files {
+		out, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out.Write(data)
+	}
+	writer.Close()
+	return buffer.Bytes()
+}
+
+func TestSignAndParse(t *testing.T) {
+	key := useKey(t)
+	bundle, err := Parse(signDirectory(t, key, map[string]string{"index.html": "<html>", "assets/app.js": "run()"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Manifest.Version != "1.2.3" || bundle.Manifest.Origin != "https://app.example.com" {
+		t.Errorf("manifest = %+v", bundle.Manifest)
+	}
+	if string(bundle.files["assets/app.js"]) != "run()" {
+		t.Errorf("assets/app.js = %q", bundle.files["assets/app.js"])
+	}
+	if _, ok := bundle.files[signatureFile]; ok {
+		t.Error("the signature is served as a file")
+	}
+}
+
+func TestParseForeignSignature(t *testing.T) {
+	useKey(t)
+	_, other, _ := ed25519.GenerateKey(nil)
+	_, err := Parse(signDirectory(t, other, map[string]string{"index.html": "<html>"}))
+	if !errors.Is(err, ErrInvalidBundle) || err.Error() != "invalid bundle: bad signature" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestParseTampered(t *testing.T) {
+	key := useKey(t)
+	signed := signDirectory(t, key, map[string]string{"index.html": "<html>"})
+
+	extra := rezip(t, signed, func(files map[string][]byte) { files["evil.js"] = []byte("steal()") })
+	if _, err := Parse(extra); err == nil || err.Error() != "invalid bundle: evil.js is not in the manifest" {
+		t.Errorf("extra file: %v", err)
+	}
+	modified := rezip(t, signed, func(files map[string][]byte) { files["index.html"] = []byte("<html><script>") })
+	if _, err := Parse(modified); err == nil || err.Error() != "invalid bundle: index.html was modified" {
+		t.Errorf("modified file: %v", err)
+	}
+	missing := rezip(t, signed, func(files map[string][]byte) { delete(files, "index.html") })
+	if _, err := Parse(missing); err == nil || err.Error() != "invalid bundle: index.html is missing" {
+		t.Errorf("missing file: %v", err)
+	}
+	escaping := rezip(t, signed, func(files map[string][]byte) { files["../index.html"] = nil })
+	if _, err := Parse(escaping); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("path outside of the bundle: %v", err)
+	}
+}
+
+// the sizes in the zip headers are not what is checked, the decompressed data is
+func TestParseOversize(t *testing.T) {
+	key := useKey(t)
+	signed := signDirectory(t, key, map[string]string{"index.html": "<html>"})
+	huge := rezip(t, signed, func(files map[string][]byte) { files["zeros.bin"] = make([]byte, maxBundleSize) })
+	if len(huge) > maxBundleSize/100 {
+		t.Fatalf("the zip itself is %d bytes", len(huge))
+	}
+	_, err := Parse(huge)
+	if !errors.Is(err, ErrInvalidBundle) || err.Error() != "invalid bundle: larger than 104857600 bytes" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestWithoutKey(t *testing.T) {
+	previous := publicKey
+	publicKey = ""
+	defer func() { publicKey = previous }()
+	if Enabled() {
+		t.Fatal("enabled without a key")
+	}
+	if _, err := verify(map[string][]byte{}); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+// the bundle takes the place of the app, but it is still cached for the realm
+func TestServeKeepsRemoteOrigin(t *testing.T) {
+	key := useKey(t)
+	opt := &options.Options{
+		DataDirectory:   t.TempDir(),
+		LoopbackPort:    65534,
+		RealmDescriptor: &options.RealmDescriptor{App: "https://app.example.com"},
+	}
+	filename := cachePath(opt)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, signDirectory(t, key, map[string]string{"index.html": "<html>"}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := loadCached(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := serve(opt, cached, "unreachable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	defer func() { status = Status{} }()
+	if opt.GetRealmOrigin() != server.Origin || opt.GetRemoteRealmOrigin() != "https://app.example.com" {
+		t.Errorf("app %s, remote %s", opt.GetRealmOrigin(), opt.GetRemoteRealmOrigin())
+	}
+	if cachePath(opt) != filename {
+		t.Errorf("cache moved to %s", cachePath(opt))
+	}
+	if _, err := Cached(opt); err != nil {
+		t.Errorf("the cached bundle is gone while it is served: %v", err)
+	}
+}
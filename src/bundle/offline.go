@@ -0,0 +1,231 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sag-enhanced/native-app/src/helper"
+	"github.com/sag-enhanced/native-app/src/logging"
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+var logger = logging.For("bundle")
+
+// the realm publishes its current bundle here
+const remoteBundlePath = "/bundle.zip"
+
+// how long the realm gets to answer before the cached bundle is used
+const probeTimeout = 5 * time.Second
+
+// the bundle is downloaded again this often, so it is current once the realm is needed
+// and can't be reached (or can be reached again)
+const refreshInterval = 30 * time.Minute
+
+type Status struct {
+	// the frontend comes from a bundle
+	Offline bool `json:"offline"`
+	// "bundle" if the realm asks for it, "unreachable" if the realm couldn't be reached
+	Reason  string `json:"reason,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+var status = Status{}
+var statusLock = sync.Mutex{}
+
+func CurrentStatus() Status {
+	statusLock.Lock()
+	defer statusLock.Unlock()
+	return status
+}
+
+// where the last good bundle of the realm is kept
+func cachePath(opt *options.Options) string {
+	host := strings.NewReplacer(":", "_", "/", "_").Replace(strings.TrimPrefix(strings.TrimPrefix(opt.GetRemoteRealmOrigin(), "https://"), "http://"))
+	return path.Join(opt.DataDirectory, "bundles", host+".zip")
+}
+
+// decides where the frontend is loaded from: the bundle of the realm if it has one, the
+// cached bundle if the realm can't be reached, and the realm itself otherwise. when a
+// bundle is served, the realm is switched over to the local server, so everything that
+// checks origins trusts the bundle (and nothing else) as the app
+func Start(opt *options.Options) (*Server, error) {
+	realm := opt.GetRealm()
+	if realm.Bundle != "" {
+		bundle, err := Load(realm.Bundle)
+		if err != nil {
+			return nil, fmt.Errorf("bundle %s: %w", realm.Bundle, err)
+		}
+		return serve(opt, bundle, "bundle")
+	}
+	if !Enabled() {
+		return nil, nil
+	}
+
+	cached, err := loadCached(opt)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Ignoring cached bundle", "error", err)
+		}
+		go keepRefreshing(opt)
+		return nil, nil
+	}
+	if reachable(opt, opt.GetRemoteRealmOrigin()) {
+		go keepRefreshing(opt)
+		return nil, nil
+	}
+	logger.Warn("Realm is unreachable, using the cached bundle", "version", cached.Manifest.Version)
+	server, err := serve(opt, cached, "unreachable")
+	// once the realm was switched over, which it reads
+	go keepRefreshing(opt)
+	return server, err
+}
+
+// also while a cached bundle is served, so the next start gets the current one
+func keepRefreshing(opt *options.Options) {
+	Refresh(context.Background(), opt)
+	for range time.Tick(refreshInterval) {
+		Refresh(context.Background(), opt)
+	}
+}
+
+func serve(opt *options.Options, bundle *Bundle, reason string) (*Server, error) {
+	realm := *opt.GetRealm()
+	remote := opt.GetRemoteRealmOrigin()
+	if !sameOrigin(bundle.Manifest.Origin, remote) {
+		return nil, fmt.Errorf("%w: made for %s, not %s", ErrInvalidBundle, bundle.Manifest.Origin, remote)
+	}
+	server, err := Serve(bundle, opt.LoopbackPort+1)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Serving bundle", "version", bundle.Manifest.Version, "origin", server.Origin)
+
+	realm.RemoteApp = remote
+	realm.App = server.Origin
+	opt.RealmDescriptor = &realm
+
+	statusLock.Lock()
+	status = Status{Offline: true, Reason: reason, Version: bundle.Manifest.Version}
+	statusLock.Unlock()
+	return server, nil
+}
+
+func loadCached(opt *options.Options) (*Bundle, error) {
+	bundle, err := Load(cachePath(opt))
+	if err != nil {
+		return nil, err
+	}
+	if !sameOrigin(bundle.Manifest.Origin, opt.GetRemoteRealmOrigin()) {
+		return nil, fmt.Errorf("%w: made for %s", ErrInvalidBundle, bundle.Manifest.Origin)
+	}
+	return bundle, nil
+}
+
+// any answer counts, even an error page means the network is there
+func reachable(opt *options.Options, origin string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, origin, nil)
+	if err != nil {
+		return false
+	}
+	response, err := helper.NewHttpClient(opt).Do(request)
+	if err != nil {
+		logger.Debug("Realm probe failed", "error", err)
+		return false
+	}
+	response.Body.Close()
+	return true
+}
+
+// downloads the current bundle of the realm and keeps it if it verifies; the cached one
+// is only replaced by a good one
+func Refresh(ctx context.Context, opt *options.Options) error {
+	err := refresh(ctx, opt)
+	if err != nil {
+		logger.Debug("Failed to refresh bundle", "error", err)
+	}
+	return err
+}
+
+func refresh(ctx context.Context, opt *options.Options) error {
+	filename := cachePath(opt)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, opt.GetRemoteRealmOrigin()+remoteBundlePath, nil)
+	if err != nil {
+		return err
+	}
+	// the etag is only good as long as the bundle it belongs to is
+	if _, err := loadCached(opt); err == nil {
+		if etag, err := os.ReadFile(filename + ".etag"); err == nil {
+			request.Header.Set("If-None-Match", string(etag))
+		}
+	}
+	response, err := helper.NewHttpClient(opt).Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", request.URL, response.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(response.Body, maxBundleSize+1))
+	if err != nil {
+		return err
+	}
+	if len(content) > maxBundleSize {
+		return fmt.Errorf("%w: larger than %d bytes", ErrInvalidBundle, maxBundleSize)
+	}
+	bundle, err := Parse(content)
+	if err != nil {
+		return err
+	}
+	if !sameOrigin(bundle.Manifest.Origin, opt.GetRemoteRealmOrigin()) {
+		return fmt.Errorf("%w: made for %s", ErrInvalidBundle, bundle.Manifest.Origin)
+	}
+
+	if err := os.MkdirAll(path.Dir(filename), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename+".tmp", content, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(filename+".tmp", filename); err != nil {
+		os.Remove(filename + ".tmp")
+		return err
+	}
+	if etag := response.Header.Get("ETag"); etag != "" {
+		os.WriteFile(filename+".etag", []byte(etag), 0644)
+	} else {
+		os.Remove(filename + ".etag")
+	}
+	logger.Info("Cached bundle", "version", bundle.Manifest.Version)
+	return nil
+}
+
+// for doctor; the cached bundle of the realm, if there is a good one
+func Cached(opt *options.Options) (*Bundle, error) {
+	if !Enabled() {
+		return nil, ErrNoKey
+	}
+	return loadCached(opt)
+}
+
+// origins are compared as the realm normalizes them
+func sameOrigin(a string, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && ua.Scheme == ub.Scheme && strings.EqualFold(ua.Host, ub.Host)
+}
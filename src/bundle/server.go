@@ -0,0 +1,77 @@
+package bundle
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// serves a verified bundle from memory on localhost, so nothing on disk can be swapped
+// out after it was checked
+
+type Server struct {
+	Bundle *Bundle
+	// what the page runs on instead of the realm origin
+	Origin string
+
+	server *http.Server
+}
+
+// tries the given port first, so the origin (and with it localStorage) stays the same
+// between launches
+func Serve(bundle *Bundle, port uint16) (*Server, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		logger.Warn("Bundle port is taken, using a random one", "port", port, "error", err)
+		if listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Server{
+		Bundle: bundle,
+		Origin: "http://" + listener.Addr().String(),
+	}
+	s.server = &http.Server{Handler: s}
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = indexFile
+	}
+	content, ok := s.Bundle.files[name]
+	if !ok {
+		// routes of the frontend, everything with an extension is a missing file
+		if path.Ext(name) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		name = indexFile
+		content = s.Bundle.files[indexFile]
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method == http.MethodGet {
+		w.Write(content)
+	}
+}
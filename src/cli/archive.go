@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// export/import copy the files as they are on disk (so encrypted data stays encrypted and
+// needs the same passwords after the import). logs, browser profiles, extensions and caches
+// are left out, they are either recreated or downloaded again
+
+var archivedFiles = []string{"manifest.json", "config.json", "sage2.id", "sage.id"}
+var archivedDirectories = []string{"data", "files"}
+
+func isArchived(name string) bool {
+	if strings.HasSuffix(name, ".tmp") || strings.HasSuffix(name, ".bkp") {
+		return false
+	}
+	for _, file := range archivedFiles {
+		if name == file {
+			return true
+		}
+	}
+	for _, directory := range archivedDirectories {
+		if strings.HasPrefix(name, directory+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func exportData(env *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	var output io.Writer = os.Stdout
+	if args[0] != "-" {
+		out, err := os.OpenFile(args[0], os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		output = out
+	}
+
+	archive := zip.NewWriter(output)
+	count := 0
+	root := env.options.DataDirectory
+	err := filepath.WalkDir(root, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(root, filename)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relative)
+		if !isArchived(name) {
+			return nil
+		}
+
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		writer, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(content)
+		count++
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d file(s)\n", count)
+	return nil
+}
+
+func importData(env *env, args []string) error {
+	force := len(args) > 0 && args[0] == "-force"
+	if force {
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	root := env.options.DataDirectory
+	if !force {
+		for _, file := range []string{"manifest.json", "sage2.id", "sage.id"} {
+			if _, err := os.Stat(path.Join(root, file)); err == nil {
+				return fmt.Errorf("the data directory already has a %s; use -force to overwrite it", file)
+			}
+		}
+	}
+
+	var archive *zip.Reader
+	if args[0] == "-" {
+		// zip needs random access
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		if archive, err = zip.NewReader(bytes.NewReader(content), int64(len(content))); err != nil {
+			return err
+		}
+	} else {
+		reader, err := zip.OpenReader(args[0])
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		archive = &reader.Reader
+	}
+
+	// everything is checked before anything is written; backslashes are separators on
+	// windows, so they could climb out of the data directory
+	for _, entry := range archive.File {
+		if !entry.FileInfo().IsDir() && (!fs.ValidPath(entry.Name) || strings.Contains(entry.Name, `\`) || !isArchived(entry.Name)) {
+			return fmt.Errorf("unexpected file in archive: %q", entry.Name)
+		}
+	}
+
+	// with -force nothing of the old data may survive next to the imported one (a stale
+	// manifest would lock the imported data behind the old passwords)
+	aside := ""
+	if force {
+		var err error
+		if aside, err = moveAside(root); err != nil {
+			return err
+		}
+	}
+
+	count := 0
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		if err := extract(entry, filepath.Join(root, filepath.FromSlash(entry.Name))); err != nil {
+			if aside != "" {
+				if restoreErr := restoreAside(root, aside); restoreErr != nil {
+					return fmt.Errorf("%s: %w (the previous data is in %s: %v)", entry.Name, err, aside, restoreErr)
+				}
+			}
+			return fmt.Errorf("%s: %w", entry.Name, err)
+		}
+		count++
+	}
+	if aside != "" {
+		os.RemoveAll(aside)
+	}
+	fmt.Fprintf(os.Stderr, "imported %d file(s)\n", count)
+	return nil
+}
+
+// moves everything an archive covers into a new directory in root; renamed, so it is
+// quick and can be undone
+func moveAside(root string) (string, error) {
+	aside, err := os.MkdirTemp(root, ".import-")
+	if err != nil {
+		return "", err
+	}
+	names := append(archivedFiles, archivedDirectories...)
+	for i, name := range names {
+		err := os.Rename(filepath.Join(root, name), filepath.Join(aside, name))
+		if err != nil && !os.IsNotExist(err) {
+			// nothing was imported yet, only what was moved goes back
+			for _, moved := range names[:i] {
+				os.Rename(filepath.Join(aside, moved), filepath.Join(root, moved))
+			}
+			os.Remove(aside)
+			return "", err
+		}
+	}
+	return aside, nil
+}
+
+// drops what was imported so far and puts the previous data back
+func restoreAside(root string, aside string) error {
+	for _, name := range append(archivedFiles, archivedDirectories...) {
+		if _, err := os.Lstat(filepath.Join(aside, name)); err != nil {
+			// there was none, so anything there now was imported
+			os.RemoveAll(filepath.Join(root, name))
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, name)); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(aside, name), filepath.Join(root, name)); err != nil {
+			return err
+		}
+	}
+	return os.Remove(aside)
+}
+
+func extract(entry *zip.File, filename string) error {
+	reader, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	// written next to the target first, so a broken archive doesn't leave half a file behind
+	out, err := os.OpenFile(filename+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, reader)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filename + ".tmp")
+		return err
+	}
+	// a stale backup would be preferred over the imported file if it ever can't be read
+	os.Remove(filename + ".bkp")
+	if err := os.Rename(filename+".tmp", filename); err != nil {
+		os.Remove(filename + ".tmp")
+		return err
+	}
+	return nil
+}
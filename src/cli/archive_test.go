@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+func testEnv(t *testing.T, files map[string]string) *env {
+	root := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &env{options: &options.Options{DataDirectory: root}}
+}
+
+func TestExportImport(t *testing.T) {
+	source := testEnv(t, map[string]string{
+		"config.json":          "{}",
+		"sage2.id":             "identity",
+		"data/settings":        "value",
+		"data/settings.bkp":    "old value",
+		"files/avatars/me.png": "png",
+		"logs/sage.log":        "log",
+		"profiles/chrome/0/x":  "cookies",
+	})
+	archivePath := filepath.Join(t.TempDir(), "export.zip")
+	if err := exportData(source, []string{archivePath}); err != nil {
+		t.Fatal(err)
+	}
+
+	target := testEnv(t, nil)
+	if err := importData(target, []string{archivePath}); err != nil {
+		t.Fatal(err)
+	}
+	root := target.options.DataDirectory
+	for _, name := range []string{"config.json", "sage2.id", "data/settings", "files/avatars/me.png"} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s wasn't imported: %v", name, err)
+		}
+	}
+	// backups, logs and browser profiles stay where they are
+	for _, name := range []string{"data/settings.bkp", "logs", "profiles"} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(name))); !os.IsNotExist(err) {
+			t.Errorf("%s was imported", name)
+		}
+	}
+
+	// an identity is never replaced by accident
+	if err := importData(target, []string{archivePath}); err == nil {
+		t.Error("imported over an existing identity without -force")
+	}
+}
+
+// an archive is refused as a whole when one of its names could end up outside of
+// the data directory or isn't something export would have written
+func TestImportRefusesUnexpectedNames(t *testing.T) {
+	for _, name := range []string{"../evil", "data/../../evil", "/etc/evil", `data/..\..\evil`, "logs/sage.log", "data/x.tmp"} {
+		archivePath := filepath.Join(t.TempDir(), "evil.zip")
+		out, err := os.Create(archivePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archive := zip.NewWriter(out)
+		archive.Create("data/good")
+		archive.Create(name)
+		archive.Close()
+		out.Close()
+
+		target := testEnv(t, nil)
+		if err := importData(target, []string{archivePath}); err == nil {
+			t.Errorf("%q was accepted", name)
+		}
+		if entries, _ := os.ReadDir(target.options.DataDirectory); len(entries) != 0 {
+			t.Errorf("%q: something was written before the archive was refused", name)
+		}
+	}
+}
+
+// -force leaves nothing of the previous data behind, unless the import fails
+func TestImportForce(t *testing.T) {
+	source := testEnv(t, map[string]string{"sage2.id": "new identity", "data/settings": "new"})
+	archivePath := filepath.Join(t.TempDir(), "export.zip")
+	if err := exportData(source, []string{archivePath}); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := map[string]string{
+		"manifest.json": "{}",
+		"sage2.id":      "old identity",
+		"data/stale":    "old",
+		"files/old.png": "png",
+		"logs/sage.log": "log",
+	}
+	target := testEnv(t, previous)
+	root := target.options.DataDirectory
+	if err := importData(target, []string{"-force", archivePath}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"manifest.json", "data/stale", "files"} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(name))); !os.IsNotExist(err) {
+			t.Errorf("%s survived the import", name)
+		}
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "sage2.id")); string(content) != "new identity" {
+		t.Errorf("sage2.id = %q", content)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(root, ".import-*")); len(entries) != 0 {
+		t.Errorf("left behind: %v", entries)
+	}
+
+	// the last entry doesn't match its checksum, so it fails after the others were written
+	brokenPath := filepath.Join(t.TempDir(), "broken.zip")
+	out, err := os.Create(brokenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(out)
+	writer, _ := archive.Create("data/settings")
+	writer.Write([]byte("new"))
+	writer, _ = archive.CreateRaw(&zip.FileHeader{Name: "data/broken", Method: zip.Store, CRC32: 1, CompressedSize64: 3, UncompressedSize64: 3})
+	writer.Write([]byte("abc"))
+	archive.Close()
+	out.Close()
+
+	target = testEnv(t, previous)
+	root = target.options.DataDirectory
+	if err := importData(target, []string{"-force", brokenPath}); err == nil {
+		t.Fatal("the broken archive was imported")
+	}
+	for name, content := range previous {
+		if got, _ := os.ReadFile(filepath.Join(root, filepath.FromSlash(name))); string(got) != content {
+			t.Errorf("%s = %q after the failed import", name, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "data", "settings")); !os.IsNotExist(err) {
+		t.Error("a file of the failed import was kept")
+	}
+	if entries, _ := filepath.Glob(filepath.Join(root, ".import-*")); len(entries) != 0 {
+		t.Errorf("left behind: %v", entries)
+	}
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sag-enhanced/native-app/src/bundle"
+)
+
+// bundles are verified with the key compiled into this build, so verify only passes for
+// bundles that this build would also serve
+
+func bundleVerify(env *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	loaded, err := bundle.Load(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version %s for %s, %d file(s)\n", loaded.Manifest.Version, loaded.Manifest.Origin, len(loaded.Manifest.Files))
+	return nil
+}
+
+func bundleSign(env *env, args []string) error {
+	if len(args) != 4 {
+		return errUsage
+	}
+	// the seed (32 bytes) or the whole private key (64 bytes), base64
+	encoded := strings.TrimSpace(os.Getenv("SAGE_BUNDLE_KEY"))
+	if encoded == "" {
+		return errors.New("SAGE_BUNDLE_KEY is not set")
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("SAGE_BUNDLE_KEY: %w", err)
+	}
+	var key ed25519.PrivateKey
+	switch len(raw) {
+	case ed25519.SeedSize:
+		key = ed25519.NewKeyFromSeed(raw)
+	case ed25519.PrivateKeySize:
+		key = ed25519.PrivateKey(raw)
+	default:
+		return errors.New("SAGE_BUNDLE_KEY is not an ed25519 private key")
+	}
+
+	directory, origin, version, output := args[0], args[1], args[2], args[3]
+	content, err := bundle.Sign(directory, origin, version, key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, content, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "public key: %s\n", base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)))
+	return nil
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sag-enhanced/native-app/src/file"
+	"github.com/sag-enhanced/native-app/src/instance"
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// subcommands for scripts and support: `sage [flags] <command> [args]`. they work on the
+// data directory directly, without a window, and refuse to run while the app is using it
+
+var errUsage = errors.New("invalid arguments")
+
+// the command failed and has already said why
+var errReported = errors.New("failed")
+
+type command struct {
+	name  string
+	usage string
+	// takes the lock of the data directory, so the app can't write at the same time
+	exclusive bool
+	run       func(env *env, args []string) error
+}
+
+var commands = []command{
+	{name: "doctor", usage: "[-json]", run: doctorCommand},
+	{name: "data list", exclusive: true, run: dataList},
+	{name: "data get", usage: "<key>", exclusive: true, run: dataGet},
+	{name: "data set", usage: "<key> [value] (value from stdin if omitted)", exclusive: true, run: dataSet},
+	{name: "data delete", usage: "<key>", exclusive: true, run: dataDelete},
+	{name: "encryption status", exclusive: true, run: encryptionStatus},
+	{name: "encryption enable", exclusive: true, run: encryptionEnable},
+	{name: "encryption disable", exclusive: true, run: encryptionDisable},
+	{name: "encryption unlock", exclusive: true, run: encryptionUnlock},
+	{name: "id show", exclusive: true, run: idShow},
+	{name: "export", usage: "<file.zip | ->", exclusive: true, run: exportData},
+	{name: "import", usage: "[-force] <file.zip | ->", exclusive: true, run: importData},
+	{name: "bundle verify", usage: "<bundle.zip | directory>", run: bundleVerify},
+	{name: "bundle sign", usage: "<directory> <origin> <version> <bundle.zip> (key from SAGE_BUNDLE_KEY)", run: bundleSign},
+}
+
+type env struct {
+	options *options.Options
+	// only for exclusive commands
+	fm *file.FileManager
+}
+
+// whether the first positional argument is one of ours
+func IsCommand(name string) bool {
+	for _, c := range commands {
+		if strings.Fields(c.name)[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runs the command and returns the exit code
+func Run(opt *options.Options, args []string) int {
+	c, rest, ok := findCommand(args)
+	if !ok {
+		printUsage(args[0])
+		return 2
+	}
+
+	err := c.execute(opt, rest)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(os.Stderr, "usage:", c.synopsis())
+		return 2
+	}
+	if err != nil {
+		if !errors.Is(err, errReported) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return 1
+	}
+	return 0
+}
+
+func findCommand(args []string) (*command, []string, bool) {
+	for i, c := range commands {
+		words := strings.Fields(c.name)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			return &commands[i], args[len(words):], true
+		}
+	}
+	return nil, nil, false
+}
+
+func (c *command) execute(opt *options.Options, args []string) error {
+	if !c.exclusive {
+		return c.run(&env{options: opt}, args)
+	}
+
+	if err := os.MkdirAll(opt.DataDirectory, 0755); err != nil {
+		return err
+	}
+	release, err := instance.Lock(opt)
+	if errors.Is(err, instance.ErrAlreadyRunning) {
+		return fmt.Errorf("%w; close SAGE first", err)
+	}
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	fm, err := file.NewFileManager(opt)
+	if err != nil {
+		return err
+	}
+	return c.run(&env{options: opt, fm: fm}, args)
+}
+
+func (c *command) synopsis() string {
+	return strings.TrimSpace(fmt.Sprintf("sage [flags] %s %s", c.name, c.usage))
+}
+
+func printUsage(group string) {
+	fmt.Fprintln(os.Stderr, "usage:")
+	for _, c := range commands {
+		if strings.Fields(c.name)[0] == group {
+			fmt.Fprintln(os.Stderr, " ", c.synopsis())
+		}
+	}
+}
+
+// asks for the password if the data directory is encrypted
+func (e *env) unlock() error {
+	if e.fm.Manifest == nil {
+		return nil
+	}
+	password, err := readPassword("Password: ")
+	if err != nil {
+		return err
+	}
+	return e.fm.TryLoadKey(password)
+}
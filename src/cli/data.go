@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// the key/value storage of the frontend (Get/Set), one file per key in data/
+
+func checkKey(key string) error {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	return nil
+}
+
+func dataList(env *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	files, err := os.ReadDir(path.Join(env.options.DataDirectory, "data"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	keys := []string{}
+	for _, file := range files {
+		if key, ok := strings.CutSuffix(file.Name(), ".dat"); ok && !file.IsDir() {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Println(key)
+	}
+	return nil
+}
+
+func dataGet(env *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if err := checkKey(args[0]); err != nil {
+		return err
+	}
+	if err := env.unlock(); err != nil {
+		return err
+	}
+	value, err := env.fm.ReadFile(env.fm.GetFilename(args[0]))
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(value)
+	return err
+}
+
+func dataSet(env *env, args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errUsage
+	}
+	if err := checkKey(args[0]); err != nil {
+		return err
+	}
+	// before reading the value, the password may be the first line of stdin
+	if err := env.unlock(); err != nil {
+		return err
+	}
+	var value []byte
+	if len(args) == 2 {
+		value = []byte(args[1])
+	} else {
+		var err error
+		if value, err = io.ReadAll(stdin); err != nil {
+			return err
+		}
+	}
+	return env.fm.WriteFile(env.fm.GetFilename(args[0]), value, false)
+}
+
+func dataDelete(env *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if err := checkKey(args[0]); err != nil {
+		return err
+	}
+	filename := env.fm.GetFilename(args[0])
+	if err := os.Remove(filename); err != nil {
+		return err
+	}
+	// the backups would be restored on the next read otherwise
+	os.Remove(filename + ".bkp")
+	os.Remove(filename + ".tmp")
+	return nil
+}
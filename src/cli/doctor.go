@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sag-enhanced/native-app/src/doctor"
+)
+
+func doctorCommand(env *env, args []string) error {
+	asJson := false
+	for _, arg := range args {
+		if arg != "-json" {
+			return errUsage
+		}
+		asJson = true
+	}
+
+	report := doctor.Run(context.Background(), env.options)
+	if asJson {
+		// meant to be attached to bug reports
+		encoded, err := json.MarshalIndent(report.Redacted(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(encoded))
+	} else {
+		report.Print(os.Stdout)
+	}
+	if report.Failed() {
+		return errReported
+	}
+	return nil
+}
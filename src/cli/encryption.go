@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+)
+
+func encryptionStatus(env *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	if env.fm.Manifest == nil {
+		fmt.Println("disabled")
+		return nil
+	}
+	fmt.Printf("enabled (%d password(s))\n", len(env.fm.Manifest.Keys))
+	return nil
+}
+
+// only checks the password; there is nothing that stays unlocked after the command
+func encryptionUnlock(env *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	if env.fm.Manifest == nil {
+		return errors.New("encryption is not enabled")
+	}
+	if err := env.unlock(); err != nil {
+		return err
+	}
+	fmt.Println("password is valid")
+	return nil
+}
+
+func encryptionEnable(env *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	// a new key would leave everything that is encrypted with the old one unreadable
+	if env.fm.Manifest != nil {
+		return errors.New("encryption is already enabled (disable it first to change the passwords)")
+	}
+	passwords, err := readNewPasswords()
+	if err != nil {
+		return err
+	}
+	if len(passwords) == 0 {
+		return errNoPassword
+	}
+	if err := env.fm.CreateKey(passwords); err != nil {
+		return err
+	}
+	return reportFileErrors(env.fm.UpdateFiles(false))
+}
+
+func encryptionDisable(env *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	if env.fm.Manifest == nil {
+		return errors.New("encryption is not enabled")
+	}
+	if err := env.unlock(); err != nil {
+		return err
+	}
+	if err := os.Remove(path.Join(env.options.DataDirectory, "manifest.json")); err != nil {
+		return err
+	}
+	return reportFileErrors(env.fm.UpdateFiles(true))
+}
+
+func reportFileErrors(errs []error) error {
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d file(s) could not be updated", len(errs))
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/sag-enhanced/native-app/src/identity"
+)
+
+func idShow(env *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	// loading creates an identity if there is none, which is not what anyone asking for it wants
+	_, errNew := os.Stat(path.Join(env.options.DataDirectory, "sage2.id"))
+	_, errOld := os.Stat(path.Join(env.options.DataDirectory, "sage.id"))
+	if errNew != nil && errOld != nil {
+		return errors.New("no identity in this data directory yet")
+	}
+	if err := env.unlock(); err != nil {
+		return err
+	}
+	id, err := identity.LoadIdentity(env.fm)
+	if err != nil {
+		return err
+	}
+	fmt.Println(id.Id())
+	return nil
+}
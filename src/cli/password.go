@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// passwords come from SAGE_PASSWORD, the terminal (without echo) or, in scripts, from
+// the next line of stdin
+
+const passwordEnv = "SAGE_PASSWORD"
+
+// new passwords for `encryption enable`, in the same order of preference
+const newPasswordEnv = "SAGE_NEW_PASSWORD"
+
+var stdin = bufio.NewReader(os.Stdin)
+
+var errNoPassword = errors.New("no password given")
+
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func readPassword(prompt string) (string, error) {
+	if password, ok := os.LookupEnv(passwordEnv); ok {
+		return password, nil
+	}
+	if isTerminal(int(os.Stdin.Fd())) {
+		return promptHidden(prompt)
+	}
+	password, err := readLine()
+	if err == io.EOF || password == "" {
+		return "", errNoPassword
+	}
+	return password, err
+}
+
+// every non-empty line of stdin is another password
+func readNewPasswords() ([]string, error) {
+	if password, ok := os.LookupEnv(newPasswordEnv); ok {
+		return []string{password}, nil
+	}
+	if isTerminal(int(os.Stdin.Fd())) {
+		password, err := promptHidden("New password: ")
+		if err != nil {
+			return nil, err
+		}
+		repeated, err := promptHidden("Repeat password: ")
+		if err != nil {
+			return nil, err
+		}
+		if password != repeated {
+			return nil, errors.New("passwords don't match")
+		}
+		return []string{password}, nil
+	}
+
+	passwords := []string{}
+	for {
+		line, err := readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line != "" {
+			passwords = append(passwords, line)
+		}
+	}
+	return passwords, nil
+}
+
+func promptHidden(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+	var password string
+	err := withoutEcho(int(os.Stdin.Fd()), func() error {
+		var err error
+		password, err = readLine()
+		return err
+	})
+	if password == "" && err == nil {
+		return "", errNoPassword
+	}
+	return password, err
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+
+package cli
+
+// passwords can only be passed via SAGE_PASSWORD or stdin here
+func isTerminal(fd int) bool {
+	return false
+}
+
+func withoutEcho(fd int, read func() error) error {
+	return read()
+}
@@ -0,0 +1,21 @@
+package cli
+
+import "golang.org/x/sys/windows"
+
+func isTerminal(fd int) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(fd), &mode) == nil
+}
+
+func withoutEcho(fd int, read func() error) error {
+	var mode uint32
+	if err := windows.GetConsoleMode(windows.Handle(fd), &mode); err != nil {
+		return err
+	}
+	hidden := mode&^windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT | windows.ENABLE_LINE_INPUT
+	if err := windows.SetConsoleMode(windows.Handle(fd), hidden); err != nil {
+		return err
+	}
+	defer windows.SetConsoleMode(windows.Handle(fd), mode)
+	return read()
+}
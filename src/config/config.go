@@ -0,0 +1,334 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sag-enhanced/native-app/src/logging"
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// settings are layered: defaults, then config.json in the data directory, then SAGE_*
+// environment variables, then whatever was passed as a flag. the keys are the names of
+// the flags (the environment variable of "forcebrowser" is SAGE_FORCEBROWSER)
+
+const (
+	configFile = "config.json"
+	envPrefix  = "SAGE_"
+)
+
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindPort
+)
+
+type setting struct {
+	key  string
+	kind kind
+	// may be changed from the frontend; nothing that runs commands or decides who we trust
+	editable bool
+	// value is a string, bool or uint16, depending on the kind
+	set func(opt *options.Options, value any) error
+	get func(opt *options.Options) any
+}
+
+var settings = []setting{
+	{key: "realm", kind: kindString,
+		set: func(opt *options.Options, value any) error { opt.Realm = value.(string); return nil },
+		get: func(opt *options.Options) any { return opt.Realm }},
+	{key: "ui", kind: kindString,
+		set: func(opt *options.Options, value any) error {
+			uis := []string{options.WebviewUI, options.PlaywrightUI, options.StdioUI, options.SocketUI}
+			if !slices.Contains(uis, value.(string)) {
+				return fmt.Errorf("unknown UI %q (expected one of %s)", value, strings.Join(uis, ", "))
+			}
+			opt.UI = value.(string)
+			return nil
+		},
+		get: func(opt *options.Options) any { return opt.UI }},
+	{key: "socket", kind: kindString,
+		set: func(opt *options.Options, value any) error { opt.UISocket = value.(string); return nil },
+		get: func(opt *options.Options) any { return opt.UISocket }},
+	{key: "open", kind: kindString,
+		set: func(opt *options.Options, value any) error {
+			command := strings.Fields(value.(string))
+			if len(command) == 0 {
+				return errors.New("must not be empty")
+			}
+			opt.OpenCommand = command
+			return nil
+		},
+		get: func(opt *options.Options) any { return strings.Join(opt.OpenCommand, " ") }},
+	{key: "forcebrowser", kind: kindString,
+		set: func(opt *options.Options, value any) error { opt.ForceBrowser = value.(string); return nil },
+		get: func(opt *options.Options) any { return opt.ForceBrowser }},
+	{key: "proxybypasslist", kind: kindString, editable: true,
+		set: func(opt *options.Options, value any) error { opt.ProxyBypassList = value.(string); return nil },
+		get: func(opt *options.Options) any { return opt.ProxyBypassList }},
+	{key: "loopback", kind: kindPort,
+		set: func(opt *options.Options, value any) error { opt.LoopbackPort = value.(uint16); return nil },
+		get: func(opt *options.Options) any { return opt.LoopbackPort }},
+	{key: "log", kind: kindString, editable: true,
+		set: func(opt *options.Options, value any) error {
+			if _, err := logging.ParseLevels(value.(string), false); err != nil {
+				return err
+			}
+			opt.LogLevels = value.(string)
+			return nil
+		},
+		get: func(opt *options.Options) any { return opt.LogLevels }},
+	{key: "verbose", kind: kindBool, editable: true,
+		set: func(opt *options.Options, value any) error { opt.Verbose = value.(bool); return nil },
+		get: func(opt *options.Options) any { return opt.Verbose }},
+	{key: "steamdev", kind: kindBool, editable: true,
+		set: func(opt *options.Options, value any) error { opt.SteamDev = value.(bool); return nil },
+		get: func(opt *options.Options) any { return opt.SteamDev }},
+	{key: "nocompress", kind: kindBool, editable: true,
+		set: func(opt *options.Options, value any) error { opt.NoCompress = value.(bool); return nil },
+		get: func(opt *options.Options) any { return opt.NoCompress }},
+	{key: "lockonexit", kind: kindBool, editable: true,
+		set: func(opt *options.Options, value any) error { opt.LockOnExit = value.(bool); return nil },
+		get: func(opt *options.Options) any { return opt.LockOnExit }},
+	{key: "noregister", kind: kindBool, editable: true,
+		set: func(opt *options.Options, value any) error { opt.NoRegister = value.(bool); return nil },
+		get: func(opt *options.Options) any { return opt.NoRegister }},
+	{key: "closetotray", kind: kindBool, editable: true,
+		set: func(opt *options.Options, value any) error { opt.CloseToTray = value.(bool); return nil },
+		get: func(opt *options.Options) any { return opt.CloseToTray }},
+	{key: "playwrightheadless", kind: kindBool,
+		set: func(opt *options.Options, value any) error { opt.PlaywrightHeadless = value.(bool); return nil },
+		get: func(opt *options.Options) any { return opt.PlaywrightHeadless }},
+	{key: "playwrighttrace", kind: kindBool,
+		set: func(opt *options.Options, value any) error { opt.PlaywrightTrace = value.(bool); return nil },
+		get: func(opt *options.Options) any { return opt.PlaywrightTrace }},
+}
+
+var ErrUnknownKey = errors.New("unknown setting")
+var ErrNotEditable = errors.New("setting can't be changed from the app")
+var ErrInvalidValue = errors.New("invalid value")
+
+type Setting struct {
+	Key string `json:"key"`
+	// what the app is running with
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+	// what is in the config file (applies from the next launch on)
+	Saved    any  `json:"saved"`
+	Editable bool `json:"editable"`
+}
+
+// where each setting came from, filled by Load
+var sources = map[string]string{}
+var fileLock = sync.Mutex{}
+
+func findSetting(key string) (*setting, bool) {
+	for i := range settings {
+		if settings[i].key == key {
+			return &settings[i], true
+		}
+	}
+	return nil, false
+}
+
+// the environment variable for a setting
+func EnvName(key string) string {
+	return envPrefix + strings.ToUpper(key)
+}
+
+// layers the config file and the environment beneath the flags that were set explicitly
+func Load(opt *options.Options, flags map[string]bool) error {
+	return load(opt, flags, sources)
+}
+
+// checks config.json and the environment without applying anything
+func Validate(dataDirectory string) error {
+	scratch := options.NewOptions()
+	scratch.DataDirectory = dataDirectory
+	return load(scratch, map[string]bool{}, map[string]string{})
+}
+
+func load(opt *options.Options, flags map[string]bool, sources map[string]string) error {
+	file, err := readFile(opt.DataDirectory)
+	if err != nil {
+		return err
+	}
+	for key := range file {
+		if _, ok := findSetting(key); !ok {
+			return fmt.Errorf("%s: %q: %w", configFile, key, ErrUnknownKey)
+		}
+	}
+
+	for _, s := range settings {
+		sources[s.key] = SourceDefault
+		if flags[s.key] {
+			sources[s.key] = SourceFlag
+			continue
+		}
+		if raw, ok := file[s.key]; ok {
+			if err := s.apply(opt, raw); err != nil {
+				return fmt.Errorf("%s: %q: %w", configFile, s.key, err)
+			}
+			sources[s.key] = SourceFile
+		}
+		if env, ok := os.LookupEnv(EnvName(s.key)); ok {
+			value, err := s.parseEnv(env)
+			if err == nil {
+				err = s.set(opt, value)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", EnvName(s.key), err)
+			}
+			sources[s.key] = SourceEnv
+		}
+	}
+	return nil
+}
+
+func (s *setting) apply(opt *options.Options, raw json.RawMessage) error {
+	value, err := s.decode(raw)
+	if err != nil {
+		return err
+	}
+	return s.set(opt, value)
+}
+
+func (s *setting) decode(raw json.RawMessage) (any, error) {
+	switch s.kind {
+	case kindBool:
+		var value bool
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return value, nil
+	case kindPort:
+		var value uint16
+		if err := json.Unmarshal(raw, &value); err != nil || value == 0 {
+			return nil, errors.New("must be a port number (1-65535)")
+		}
+		return value, nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, errors.New("must be a string")
+	}
+	return value, nil
+}
+
+func (s *setting) parseEnv(env string) (any, error) {
+	switch s.kind {
+	case kindBool:
+		value, err := strconv.ParseBool(env)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return value, nil
+	case kindPort:
+		value, err := strconv.ParseUint(env, 10, 16)
+		if err != nil || value == 0 {
+			return nil, errors.New("must be a port number (1-65535)")
+		}
+		return uint16(value), nil
+	}
+	return env, nil
+}
+
+func readFile(dataDirectory string) (map[string]json.RawMessage, error) {
+	content, err := os.ReadFile(path.Join(dataDirectory, configFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]json.RawMessage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	file := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", configFile, err)
+	}
+	return file, nil
+}
+
+// every setting, with its current value and what is saved in the config file
+func List(opt *options.Options) ([]Setting, error) {
+	fileLock.Lock()
+	file, err := readFile(opt.DataDirectory)
+	fileLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	list := []Setting{}
+	for _, s := range settings {
+		entry := Setting{Key: s.key, Value: s.get(opt), Source: sources[s.key], Editable: s.editable}
+		if entry.Source == "" {
+			entry.Source = SourceDefault
+		}
+		if raw, ok := file[s.key]; ok {
+			entry.Saved, _ = s.decode(raw)
+		}
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list, nil
+}
+
+// validates the value and writes it to the config file (nil removes the key again);
+// like everything from the config file it takes effect on the next launch
+func Save(opt *options.Options, key string, value json.RawMessage) error {
+	s, ok := findSetting(key)
+	if !ok {
+		return fmt.Errorf("%q: %w", key, ErrUnknownKey)
+	}
+	if !s.editable {
+		return fmt.Errorf("%q: %w", key, ErrNotEditable)
+	}
+	remove := len(value) == 0 || bytes.Equal(value, []byte("null"))
+	if !remove {
+		// validate against a copy, the running app keeps its settings
+		scratch := *opt
+		if err := s.apply(&scratch, value); err != nil {
+			return fmt.Errorf("%q: %w: %w", key, ErrInvalidValue, err)
+		}
+	}
+
+	fileLock.Lock()
+	defer fileLock.Unlock()
+	file, err := readFile(opt.DataDirectory)
+	if err != nil {
+		return err
+	}
+	if remove {
+		delete(file, key)
+	} else {
+		file[key] = value
+	}
+	encoded, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// written to a temporary file first, so a crash never leaves half a config behind
+	filename := path.Join(opt.DataDirectory, configFile)
+	if err := os.WriteFile(filename+".tmp", append(encoded, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(filename+".tmp", filename)
+}
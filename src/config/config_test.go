@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+func writeConfig(t *testing.T, content string) *options.Options {
+	opt := options.NewOptions()
+	opt.DataDirectory = t.TempDir()
+	if err := os.WriteFile(path.Join(opt.DataDirectory, configFile), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return opt
+}
+
+// every layer wins over the ones below it: default < file < environment < flag
+func TestLoadLayers(t *testing.T) {
+	opt := writeConfig(t, `{"forcebrowser": "/from/file", "nocompress": true, "loopback": 9000}`)
+	t.Setenv(EnvName("loopback"), "9001")
+	t.Setenv(EnvName("proxybypasslist"), "*.env.example")
+	// the flag was already parsed into opt
+	opt.ForceBrowser = "/from/flag"
+
+	if err := Load(opt, map[string]bool{"forcebrowser": true}); err != nil {
+		t.Fatal(err)
+	}
+	got := sources
+	if opt.ForceBrowser != "/from/flag" || got["forcebrowser"] != SourceFlag {
+		t.Errorf("forcebrowser = %q from %s", opt.ForceBrowser, got["forcebrowser"])
+	}
+	if opt.LoopbackPort != 9001 || got["loopback"] != SourceEnv {
+		t.Errorf("loopback = %d from %s", opt.LoopbackPort, got["loopback"])
+	}
+	if opt.ProxyBypassList != "*.env.example" || got["proxybypasslist"] != SourceEnv {
+		t.Errorf("proxybypasslist = %q from %s", opt.ProxyBypassList, got["proxybypasslist"])
+	}
+	if !opt.NoCompress || got["nocompress"] != SourceFile {
+		t.Errorf("nocompress = %v from %s", opt.NoCompress, got["nocompress"])
+	}
+	if opt.Verbose || got["verbose"] != SourceDefault {
+		t.Errorf("verbose = %v from %s", opt.Verbose, got["verbose"])
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if err := Load(writeConfig(t, `{"nosuchkey": 1}`), map[string]bool{}); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unknown key: %v", err)
+	}
+	if err := Load(writeConfig(t, `{"loopback": 0}`), map[string]bool{}); err == nil {
+		t.Error("port 0 was accepted")
+	}
+	if err := Load(writeConfig(t, `{"nocompress": "yes"}`), map[string]bool{}); err == nil {
+		t.Error("a string was accepted as a bool")
+	}
+
+	t.Setenv(EnvName("nocompress"), "maybe")
+	if err := Load(writeConfig(t, `{}`), map[string]bool{}); err == nil {
+		t.Error("SAGE_NOCOMPRESS=maybe was accepted")
+	}
+	// a flag hides a broken environment variable
+	if err := Load(writeConfig(t, `{}`), map[string]bool{"nocompress": true}); err != nil {
+		t.Errorf("flag over a broken variable: %v", err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	opt := writeConfig(t, `{"nocompress": true}`)
+	if err := Save(opt, "forcebrowser", []byte(`"/usr/bin/chromium"`)); !errors.Is(err, ErrNotEditable) {
+		t.Errorf("forcebrowser runs a command, it must not be editable: %v", err)
+	}
+	if err := Save(opt, "nocompress", []byte(`"yes"`)); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("invalid value: %v", err)
+	}
+	if err := Save(opt, "nocompress", nil); err != nil {
+		t.Fatal(err)
+	}
+	file, err := readFile(opt.DataDirectory)
+	if err != nil || len(file) != 0 {
+		t.Fatalf("after removing the key: %v, %v", file, err)
+	}
+}
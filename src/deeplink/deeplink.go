@@ -0,0 +1,124 @@
+package deeplink
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// links like sage://open/some/page?x=1 are handed to the app by the OS, either as a
+// command line argument (linux, windows) or as an event (macOS). the host is the action,
+// only the ones listed below are accepted; everything else is rejected before it gets
+// anywhere near the page
+
+const Scheme = "sage"
+
+// urls longer than this are almost certainly not something we generated
+const maxLength = 2048
+
+const (
+	// navigates the realm to the path of the link
+	ActionOpen = "open"
+	// handed to the page as a sagel event
+	ActionConnect = "connect"
+)
+
+var actions = map[string]bool{
+	ActionOpen:    true,
+	ActionConnect: true,
+}
+
+var ErrInvalidLink = errors.New("invalid deep link")
+
+type Link struct {
+	Action string            `json:"action"`
+	Path   string            `json:"path"`
+	Params map[string]string `json:"params"`
+
+	rawQuery string
+}
+
+func Parse(raw string) (*Link, error) {
+	if len(raw) > maxLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidLink, maxLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLink, err)
+	}
+	if u.Scheme != Scheme {
+		return nil, fmt.Errorf("%w: scheme must be %s://", ErrInvalidLink, Scheme)
+	}
+	if u.User != nil || u.Port() != "" {
+		return nil, fmt.Errorf("%w: unexpected credentials or port", ErrInvalidLink)
+	}
+	action := strings.ToLower(u.Hostname())
+	if !actions[action] {
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidLink, action)
+	}
+
+	linkPath := u.EscapedPath()
+	if linkPath == "" {
+		linkPath = "/"
+	}
+	// no ../ tricks and nothing that could be read as another host
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment == "." || segment == ".." || strings.Contains(segment, "\\") {
+			return nil, fmt.Errorf("%w: bad path %q", ErrInvalidLink, u.Path)
+		}
+	}
+	if strings.HasPrefix(linkPath, "//") {
+		return nil, fmt.Errorf("%w: bad path %q", ErrInvalidLink, u.Path)
+	}
+
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLink, err)
+	}
+	params := map[string]string{}
+	for key, values := range query {
+		params[key] = values[0]
+	}
+	return &Link{Action: action, Path: linkPath, Params: params, rawQuery: query.Encode()}, nil
+}
+
+// the first argument that looks like a deep link
+func Find(args []string) (string, bool) {
+	for _, arg := range args {
+		if strings.HasPrefix(strings.ToLower(arg), Scheme+":") {
+			return arg, true
+		}
+	}
+	return "", false
+}
+
+// where the link points to in the realm; the path was validated by Parse, so this
+// always stays on the given origin
+func (l *Link) Target(origin string) string {
+	target := origin + l.Path
+	if l.rawQuery != "" {
+		target += "?" + l.rawQuery
+	}
+	return target
+}
+
+// links the page hasn't picked up yet; the page may not even be loaded when a link arrives,
+// so instead of handing it over directly it is told to come and get it
+var pending *Link
+var pendingLock = sync.Mutex{}
+
+func SetPending(link *Link) {
+	pendingLock.Lock()
+	pending = link
+	pendingLock.Unlock()
+}
+
+func TakePending() *Link {
+	pendingLock.Lock()
+	defer pendingLock.Unlock()
+	link := pending
+	pending = nil
+	return link
+}
@@ -0,0 +1,52 @@
+//go:build darwin && cgo
+
+package deeplink
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Foundation -framework CoreServices
+#import <Foundation/Foundation.h>
+#import <CoreServices/CoreServices.h>
+#import <objc/runtime.h>
+
+extern void deeplinkOpened(char *url);
+
+// the class is created at runtime, since the preamble may only contain static
+// definitions when something is exported from this file
+static void handleURLEvent(id self, SEL _cmd, NSAppleEventDescriptor *event, NSAppleEventDescriptor *reply) {
+	NSString *url = [[event paramDescriptorForKeyword:keyDirectObject] stringValue];
+	if (url != nil) {
+		deeplinkOpened((char *)[url UTF8String]);
+	}
+}
+
+static void listenForURLEvents(void) {
+	Class handlerClass = objc_allocateClassPair([NSObject class], "SageURLHandler", 0);
+	class_addMethod(handlerClass, @selector(handleURLEvent:withReplyEvent:), (IMP)handleURLEvent, "v@:@@");
+	objc_registerClassPair(handlerClass);
+
+	// lives for as long as the app does
+	id handler = [[handlerClass alloc] init];
+	[[NSAppleEventManager sharedAppleEventManager] setEventHandler:handler
+	                                                   andSelector:@selector(handleURLEvent:withReplyEvent:)
+	                                                 forEventClass:kInternetEventClass
+	                                                    andEventID:kAEGetURL];
+}
+*/
+import "C"
+
+// macOS doesn't pass links as arguments, LaunchServices sends a GetURL Apple Event
+// to the app instead (starting it first if needed)
+var handler func(raw string)
+
+func Listen(handle func(raw string)) {
+	handler = handle
+	C.listenForURLEvents()
+}
+
+//export deeplinkOpened
+func deeplinkOpened(url *C.char) {
+	if handler != nil {
+		handler(C.GoString(url))
+	}
+}
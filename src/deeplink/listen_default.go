@@ -0,0 +1,7 @@
+//go:build !(darwin && cgo)
+
+package deeplink
+
+// everywhere but macOS the link is passed as a command line argument, so a link that
+// arrives while the app is running comes in via a second launch
+func Listen(handle func(raw string)) {}
@@ -0,0 +1,8 @@
+//go:build !linux && !windows
+
+package deeplink
+
+// on macOS the scheme is declared in Info.plist, there is nothing to do at runtime
+func Register(executable string, args []string) error {
+	return nil
+}
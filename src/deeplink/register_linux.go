@@ -0,0 +1,59 @@
+package deeplink
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+const desktopFile = "sage.desktop"
+
+// registers the app as x-scheme-handler/sage via a .desktop file in the user's
+// applications directory; nothing is written if it's already up to date
+func Register(executable string, args []string) error {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		dataHome = path.Join(home, ".local", "share")
+	}
+	directory := path.Join(dataHome, "applications")
+	filename := path.Join(directory, desktopFile)
+
+	command := []string{quoteExec(executable)}
+	for _, arg := range args {
+		command = append(command, quoteExec(arg))
+	}
+	content := []byte("[Desktop Entry]\n" +
+		"Type=Application\n" +
+		"Name=SAGE\n" +
+		"Exec=" + strings.Join(command, " ") + " %u\n" +
+		"Terminal=false\n" +
+		"NoDisplay=true\n" +
+		"MimeType=x-scheme-handler/" + Scheme + ";\n")
+
+	if existing, err := os.ReadFile(filename); err == nil && bytes.Equal(existing, content) {
+		return nil
+	}
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		return err
+	}
+	return exec.Command("xdg-mime", "default", desktopFile, "x-scheme-handler/"+Scheme).Run()
+}
+
+// quoting as per the desktop entry spec; % has to be doubled, even inside quotes
+func quoteExec(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	if !strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`") {
+		return arg
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	return `"` + replacer.Replace(arg) + `"`
+}
@@ -0,0 +1,37 @@
+package deeplink
+
+import (
+	"strings"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// registers the app as handler of sage:// links for the current user
+// (HKCU\Software\Classes\sage), so no admin rights are needed
+func Register(executable string, args []string) error {
+	command := []string{windows.EscapeArg(executable)}
+	for _, arg := range args {
+		command = append(command, windows.EscapeArg(arg))
+	}
+	command = append(command, `"%1"`)
+
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, `Software\Classes\`+Scheme, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	if err := key.SetStringValue("", "URL:SAGE"); err != nil {
+		return err
+	}
+	if err := key.SetStringValue("URL Protocol", ""); err != nil {
+		return err
+	}
+
+	commandKey, _, err := registry.CreateKey(key, `shell\open\command`, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer commandKey.Close()
+	return commandKey.SetStringValue("", strings.Join(command, " "))
+}
@@ -0,0 +1,187 @@
+package doctor
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/sag-enhanced/native-app/src/browser"
+	"github.com/sag-enhanced/native-app/src/bundle"
+	"github.com/sag-enhanced/native-app/src/config"
+	"github.com/sag-enhanced/native-app/src/file"
+	"github.com/sag-enhanced/native-app/src/instance"
+	"github.com/sag-enhanced/native-app/src/options"
+	"github.com/sag-enhanced/native-app/src/steam"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+type check struct {
+	name string
+	run  func(ctx context.Context, opt *options.Options) Check
+}
+
+var checks = []check{
+	{"webview", checkWebview},
+	{"browser", checkBrowser},
+	{"steam", checkSteam},
+	{"data directory", checkDataDirectory},
+	{"manifest", checkManifest},
+	{"config", checkConfig},
+	{"realm", checkRealm},
+	{"offline bundle", checkBundle},
+	{"loopback port", checkLoopbackPort},
+	{"network", checkNetwork},
+}
+
+// only a problem if the app is supposed to use it
+func webviewMissing(opt *options.Options, detail string, hint string) Check {
+	if opt.UI != options.WebviewUI {
+		return warn(detail+" (not needed with the "+opt.UI+" UI)", hint)
+	}
+	return fail(detail, hint)
+}
+
+func checkBrowser(ctx context.Context, opt *options.Options) Check {
+	if opt.ForceBrowser != "" {
+		if _, err := os.Stat(opt.ForceBrowser); err != nil {
+			return fail(fmt.Sprintf("forced browser %s: %s", opt.ForceBrowser, err), "fix the path passed with -forcebrowser (or in config.json)")
+		}
+		return pass("forced to " + opt.ForceBrowser)
+	}
+	found := []string{}
+	for _, name := range []string{"chrome", "edge"} {
+		if exe, err := browser.FindInstalledBrowser(name); err == nil {
+			found = append(found, fmt.Sprintf("%s (%s)", name, exe))
+		}
+	}
+	if len(found) == 0 {
+		return warn("neither Chrome nor Edge found, only the bundled Chromium can be used", "install Google Chrome, or point -forcebrowser at a Chromium based browser")
+	}
+	return pass(strings.Join(found, ", "))
+}
+
+func checkSteam(ctx context.Context, opt *options.Options) Check {
+	exe, err := steam.LocateSteamExecutable(opt)
+	if err != nil {
+		return warn("Steam not running and not located before", "start Steam once while SAGE is running, so it can be found")
+	}
+	if _, err := os.Stat(exe); err != nil {
+		return fail(fmt.Sprintf("%s: %s", exe, err), "delete steam_executable.txt in the data directory and start Steam")
+	}
+	return pass(exe)
+}
+
+func checkDataDirectory(ctx context.Context, opt *options.Options) Check {
+	hint := "make sure the directory is writable by your user, or pick another one with -data"
+	if err := os.MkdirAll(opt.DataDirectory, 0755); err != nil {
+		return fail(err.Error(), hint)
+	}
+	probe, err := os.CreateTemp(opt.DataDirectory, "doctor-*")
+	if err != nil {
+		return fail(err.Error(), hint)
+	}
+	probe.Close()
+	if err := os.Remove(probe.Name()); err != nil {
+		return fail(err.Error(), hint)
+	}
+	return pass(opt.DataDirectory + " is writable")
+}
+
+func checkManifest(ctx context.Context, opt *options.Options) Check {
+	hint := "restore manifest.json from a backup; without it encrypted data can't be read"
+	if _, err := os.Stat(path.Join(opt.DataDirectory, "manifest.json")); os.IsNotExist(err) {
+		return pass("encryption is not enabled")
+	}
+	fm, err := file.NewFileManager(opt)
+	if err != nil {
+		return fail("manifest.json is invalid: "+err.Error(), hint)
+	}
+	if len(fm.Manifest.Keys) == 0 {
+		return fail("manifest.json has no keys", hint)
+	}
+	if _, err := hex.DecodeString(fm.Manifest.Salt); err != nil || fm.Manifest.Salt == "" {
+		return fail("manifest.json has an invalid salt", hint)
+	}
+	for _, key := range fm.Manifest.Keys {
+		if secret, err := hex.DecodeString(key.Secret); err != nil || len(secret) != 32 {
+			return fail("manifest.json has an invalid key", hint)
+		}
+	}
+	return pass(fmt.Sprintf("encryption is enabled with %d password(s)", len(fm.Manifest.Keys)))
+}
+
+func checkConfig(ctx context.Context, opt *options.Options) Check {
+	if err := config.Validate(opt.DataDirectory); err != nil {
+		return fail(err.Error(), "fix or remove the setting; the app won't start like this")
+	}
+	return pass("config.json and SAGE_* environment variables are valid")
+}
+
+func checkRealm(ctx context.Context, opt *options.Options) Check {
+	resolved := *opt
+	resolved.RealmDescriptor = nil
+	if err := resolved.LoadRealm(); err != nil {
+		return fail(err.Error(), "fix the realm file or pick another realm with -realm")
+	}
+	return pass(fmt.Sprintf("%s (identity: %s)", resolved.GetRealmOrigin(), resolved.GetIdentityOrigin()))
+}
+
+func checkBundle(ctx context.Context, opt *options.Options) Check {
+	if filename := opt.GetRealm().Bundle; filename != "" {
+		loaded, err := bundle.Load(filename)
+		if err != nil {
+			return fail(fmt.Sprintf("%s: %s", filename, err), "replace the bundle with a signed one, or remove \"bundle\" from the realm")
+		}
+		return pass(fmt.Sprintf("the realm is served from %s (version %s)", filename, loaded.Manifest.Version))
+	}
+	if !bundle.Enabled() {
+		return pass("this build has no bundle key, the app always loads the realm")
+	}
+	cached, err := bundle.Cached(opt)
+	if errors.Is(err, os.ErrNotExist) {
+		return warn("no bundle cached yet, the app won't work without network", "start the app once while online")
+	}
+	if err != nil {
+		return warn(err.Error(), "start the app once while online to replace it")
+	}
+	return pass(fmt.Sprintf("version %s is cached for when %s can't be reached", cached.Manifest.Version, opt.GetRemoteRealmOrigin()))
+}
+
+func checkLoopbackPort(ctx context.Context, opt *options.Options) Check {
+	address := fmt.Sprintf("127.0.0.1:%d", opt.LoopbackPort)
+	listener, err := net.Listen("tcp", address)
+	if err == nil {
+		listener.Close()
+		return pass(address + " is free")
+	}
+	if pid, ok := instance.RunningPid(opt); ok {
+		if pid == os.Getpid() {
+			return pass(address + " is in use by this app")
+		}
+		if exists, _ := process.PidExistsWithContext(ctx, int32(pid)); exists {
+			return warn(fmt.Sprintf("%s is in use, probably by the running SAGE instance (pid %d)", address, pid), "this is fine while SAGE is running")
+		}
+	}
+	return fail(fmt.Sprintf("%s is in use by another program", address), "close the program using the port, or pick another one with -loopback")
+}
+
+func checkNetwork(ctx context.Context, opt *options.Options) Check {
+	// the realm itself, not the bundle that may be served in its place
+	origin := opt.GetRemoteRealmOrigin()
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, origin, nil)
+	if err != nil {
+		return fail(err.Error(), "check the realm passed with -realm")
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fail(fmt.Sprintf("%s is not reachable: %s", origin, err), "check your internet connection, firewall and proxy settings")
+	}
+	response.Body.Close()
+	return pass(fmt.Sprintf("%s answered with %s", origin, response.Status))
+}
@@ -0,0 +1,123 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// `sage doctor` and the diagnostics binding: checks everything support would otherwise
+// have to ask about (webview, browsers, Steam, data directory, manifest, config, loopback
+// port, network) and says what to do about the things that are broken
+
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// each check gets this long; some of them go through the process list or the network
+const checkTimeout = 10 * time.Second
+
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+type Report struct {
+	Time    time.Time `json:"time"`
+	Build   uint32    `json:"build"`
+	Release uint32    `json:"release"`
+	OS      string    `json:"os"`
+	Arch    string    `json:"arch"`
+	UI      string    `json:"ui"`
+	Realm   string    `json:"realm"`
+	Checks  []Check   `json:"checks"`
+}
+
+func pass(detail string) Check {
+	return Check{Status: StatusPass, Detail: detail}
+}
+
+func warn(detail string, hint string) Check {
+	return Check{Status: StatusWarn, Detail: detail, Hint: hint}
+}
+
+func fail(detail string, hint string) Check {
+	return Check{Status: StatusFail, Detail: detail, Hint: hint}
+}
+
+func Run(ctx context.Context, opt *options.Options) *Report {
+	report := &Report{
+		Time:    time.Now(),
+		Build:   opt.Build,
+		Release: opt.Release,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+		UI:      opt.UI,
+		Realm:   opt.Realm,
+		Checks:  []Check{},
+	}
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		result := c.run(checkCtx, opt)
+		cancel()
+		result.Name = c.name
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+func (r *Report) Failed() bool {
+	for _, c := range r.Checks {
+		if c.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// a copy that can be attached to a bug report: the home directory and the user name
+// are replaced, so paths don't give away who the report is from
+func (r *Report) Redacted() *Report {
+	replacements := []string{}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		replacements = append(replacements, home, "~")
+	}
+	if current, err := user.Current(); err == nil {
+		// DOMAIN\user on windows; very short names would mangle everything else
+		name := current.Username[strings.LastIndex(current.Username, "\\")+1:]
+		if len(name) >= 3 {
+			replacements = append(replacements, name, "<user>")
+		}
+	}
+	replacer := strings.NewReplacer(replacements...)
+
+	redacted := *r
+	redacted.Checks = make([]Check, len(r.Checks))
+	for i, c := range r.Checks {
+		c.Detail = replacer.Replace(c.Detail)
+		c.Hint = replacer.Replace(c.Hint)
+		redacted.Checks[i] = c
+	}
+	return &redacted
+}
+
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "SAGE b%d r%d (%s/%s, %s UI, %s realm)\n\n", r.Build, r.Release, r.OS, r.Arch, r.UI, r.Realm)
+	for _, c := range r.Checks {
+		fmt.Fprintf(w, "[%s] %s: %s\n", strings.ToUpper(c.Status), c.Name, c.Detail)
+		if c.Hint != "" {
+			fmt.Fprintf(w, "       -> %s\n", c.Hint)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package doctor
+
+import (
+	"context"
+
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+func checkWebview(ctx context.Context, opt *options.Options) Check {
+	if !options.IsWebviewAvailable() {
+		return webviewMissing(opt, "WebView2 runtime not installed", "install the WebView2 runtime from https://developer.microsoft.com/microsoft-edge/webview2/, or use -ui playwright")
+	}
+	return pass("available")
+}
@@ -0,0 +1,27 @@
+//go:build linux
+
+package doctor
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// the app only looks for the headers (it segfaults without them at build time), but
+// what it needs at runtime is the library itself
+func checkWebview(ctx context.Context, opt *options.Options) Check {
+	libraries := []string{}
+	for _, pattern := range []string{"/usr/lib*/libwebkit2gtk-4.1.so.0", "/usr/lib*/*/libwebkit2gtk-4.1.so.0", "/lib*/*/libwebkit2gtk-4.1.so.0"} {
+		matches, _ := filepath.Glob(pattern)
+		libraries = append(libraries, matches...)
+	}
+	if len(libraries) == 0 {
+		return webviewMissing(opt, "libwebkit2gtk-4.1 not found", "install webkit2gtk 4.1 (eg. apt install libwebkit2gtk-4.1-0), or use -ui playwright")
+	}
+	if !options.IsWebviewAvailable() {
+		return warn("libwebkit2gtk-4.1 found, but not its headers, so the app starts with playwright by default", "install libwebkit2gtk-4.1-dev or pass -ui webview")
+	}
+	return pass(libraries[0])
+}
@@ -21,12 +21,25 @@ const (
 	FileHeaderEncryptedNoPad FileHeader = 0x3
 )
 
+var (
+	ErrLocked          = errors.New("encrypted")
+	ErrInvalidPassword = errors.New("invalid password")
+	ErrNoManifest      = errors.New("no manifest")
+	ErrCorrupted       = errors.New("corrupted")
+	ErrClosed          = errors.New("file manager is closed")
+)
+
 var fileWriterLock = sync.Mutex{}
 
 type FileManager struct {
 	Manifest *EncryptionManifest
 	Cipher   *cipher.Block
 	Options  *options.Options
+	// the master key behind Cipher, kept so Lock can zero it
+	key []byte
+
+	// set on shutdown, guarded by fileWriterLock
+	closed bool
 }
 
 func NewFileManager(options *options.Options) (*FileManager, error) {
@@ -85,6 +98,9 @@ func (fm *FileManager) WriteFile(filename string, data []byte, ignoreCipher bool
 	}
 	fileWriterLock.Lock()
 	defer fileWriterLock.Unlock()
+	if fm.closed {
+		return ErrClosed
+	}
 	parent := path.Dir(filename)
 	if _, err := os.Stat(parent); os.IsNotExist(err) {
 		if err := os.MkdirAll(parent, 0755); err != nil {
@@ -107,6 +123,13 @@ func (fm *FileManager) WriteFile(filename string, data []byte, ignoreCipher bool
 	return nil
 }
 
+// waits for the write that is in progress (if any) and refuses all further writes
+func (fm *FileManager) Close() {
+	fileWriterLock.Lock()
+	fm.closed = true
+	fileWriterLock.Unlock()
+}
+
 func (fm *FileManager) UpdateFiles(ignoreCipher bool) []error {
 	errors := []error{}
 	fileNames := []string{}
@@ -6,7 +6,6 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"os"
 	"path"
 
@@ -15,7 +14,7 @@ import (
 
 func (fm *FileManager) TryLoadKey(password string) error {
 	if fm.Manifest == nil {
-		return errors.New("no manifest")
+		return ErrNoManifest
 	}
 
 	salt, err := hex.DecodeString(fm.Manifest.Salt)
@@ -23,6 +22,7 @@ func (fm *FileManager) TryLoadKey(password string) error {
 		return err
 	}
 	key := helper.DeriveKey(password, salt)
+	defer clear(key)
 
 	control := sha256.Sum256(key)
 	encoded := hex.EncodeToString(control[:])
@@ -43,14 +43,17 @@ func (fm *FileManager) TryLoadKey(password string) error {
 
 			cipher, err := aes.NewCipher(decrypted)
 			if err != nil {
+				clear(decrypted)
 				return err
 			}
+			fm.Lock()
+			fm.key = decrypted
 			fm.Cipher = &cipher
 			return nil
 		}
 	}
 
-	return errors.New("invalid password")
+	return ErrInvalidPassword
 }
 
 func (fm *FileManager) CreateKey(passwords []string) error {
@@ -68,6 +71,7 @@ func (fm *FileManager) CreateKey(passwords []string) error {
 
 		cipher, err := aes.NewCipher(key)
 		if err != nil {
+			clear(key)
 			return err
 		}
 		encryptedMasterKey := make([]byte, 32)
@@ -75,6 +79,7 @@ func (fm *FileManager) CreateKey(passwords []string) error {
 		cipher.Encrypt(encryptedMasterKey[16:], masterKey[16:])
 
 		hashedKey := sha256.Sum256(key)
+		clear(key)
 		keys[i] = EncryptionKey{
 			Hash:   hex.EncodeToString(hashedKey[:]),
 			Secret: hex.EncodeToString(encryptedMasterKey),
@@ -99,7 +104,17 @@ func (fm *FileManager) CreateKey(passwords []string) error {
 	if err != nil {
 		return err
 	}
+	fm.Lock()
 	fm.Manifest = &manifest
+	fm.key = masterKey
 	fm.Cipher = &cipher
 	return nil
 }
+
+// forgets the key until it is loaded again, zeroing the copy we hold.
+// the key schedule inside the cipher can't be reached from here, it goes with the GC
+func (fm *FileManager) Lock() {
+	clear(fm.key)
+	fm.key = nil
+	fm.Cipher = nil
+}
@@ -0,0 +1,71 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// what CreateKey writes has to be unlockable by a later launch with any of the passwords
+func TestCreateKeyThenUnlock(t *testing.T) {
+	opt := &options.Options{DataDirectory: t.TempDir()}
+	fm, err := NewFileManager(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fm.CreateKey([]string{"hunter2", "correct horse"}); err != nil {
+		t.Fatal(err)
+	}
+	filename := path.Join(opt.DataDirectory, "data", "secret")
+	if err := fm.WriteFile(filename, []byte("plain"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, password := range []string{"hunter2", "correct horse"} {
+		restarted, err := NewFileManager(opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := restarted.ReadFile(filename); !errors.Is(err, ErrLocked) {
+			t.Fatalf("read before unlocking: %v", err)
+		}
+		if err := restarted.TryLoadKey(password); err != nil {
+			t.Fatalf("%s: %v", password, err)
+		}
+		content, err := restarted.ReadFile(filename)
+		if err != nil || string(content) != "plain" {
+			t.Fatalf("%s: read %q, %v", password, content, err)
+		}
+	}
+
+	restarted, _ := NewFileManager(opt)
+	if err := restarted.TryLoadKey("hunter3"); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("wrong password: %v", err)
+	}
+}
+
+func TestLockZeroesKey(t *testing.T) {
+	opt := &options.Options{DataDirectory: t.TempDir()}
+	fm, _ := NewFileManager(opt)
+	if err := fm.CreateKey([]string{"hunter2"}); err != nil {
+		t.Fatal(err)
+	}
+	key := fm.key
+	if len(key) != 32 || bytes.Equal(key, make([]byte, 32)) {
+		t.Fatalf("key = %x", key)
+	}
+
+	fm.Lock()
+	if fm.Cipher != nil || fm.key != nil {
+		t.Fatal("the key is still loaded")
+	}
+	if !bytes.Equal(key, make([]byte, 32)) {
+		t.Fatalf("the key wasn't zeroed: %x", key)
+	}
+	if err := fm.TryLoadKey("hunter2"); err != nil {
+		t.Fatalf("unlocking again: %v", err)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"compress/flate"
 	"crypto/cipher"
 	"crypto/rand"
-	"errors"
+	"fmt"
 	"io"
 
 	"github.com/sag-enhanced/native-app/src/helper"
@@ -13,14 +13,14 @@ import (
 
 func (fm *FileManager) unpack(data []byte) ([]byte, error) {
 	if len(data) == 0 {
-		return nil, errors.New("empty file (corrupted)")
+		return nil, fmt.Errorf("empty file (%w)", ErrCorrupted)
 	}
 	header := FileHeader(data[0])
 	if header == FileHeaderRaw {
 		return data[1:], nil
 	} else if header == FileHeaderEncrypted || header == FileHeaderEncryptedNoPad {
 		if fm.Cipher == nil {
-			return nil, errors.New("encrypted")
+			return nil, ErrLocked
 		}
 		aesCipher, err := cipher.NewGCM(*fm.Cipher)
 		if err != nil {
@@ -45,7 +45,7 @@ func (fm *FileManager) unpack(data []byte) ([]byte, error) {
 		}
 		return fm.unpack(decompressed)
 	}
-	return nil, errors.New("unknown header")
+	return nil, fmt.Errorf("unknown header (%w)", ErrCorrupted)
 }
 
 func (fm *FileManager) pack(data []byte, ignoreCipher bool) ([]byte, error) {
@@ -0,0 +1,51 @@
+package helper
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// for the requests the app makes on its own: they go through the proxy of the
+// environment, except for the hosts in -proxybypasslist (like the browsers do)
+func NewHttpClient(options *options.Options) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(request *http.Request) (*url.URL, error) {
+		if bypassProxy(options.ProxyBypassList, request.URL) {
+			return nil, nil
+		}
+		return http.ProxyFromEnvironment(request)
+	}
+	return &http.Client{Transport: transport}
+}
+
+// same rules as chromium's --proxy-bypass-list: hosts (with an optional port) that may
+// contain wildcards, and <local> for hosts without a dot
+func bypassProxy(list string, u *url.URL) bool {
+	if list == "" || list == "-" {
+		return false
+	}
+	hostname := strings.ToLower(u.Hostname())
+	for _, rule := range strings.Split(list, ";") {
+		rule = strings.ToLower(strings.TrimSpace(rule))
+		switch {
+		case rule == "":
+		case rule == "<local>":
+			if !strings.Contains(hostname, ".") {
+				return true
+			}
+		default:
+			host := hostname
+			if strings.Contains(rule, ":") {
+				host = strings.ToLower(u.Host)
+			}
+			if matched, _ := path.Match(rule, host); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// long running bindings can report progress to the frontend before they return;
+// the RPC engine puts the reporter into the context of every call
+
+type Progress struct {
+	Phase string `json:"phase"`
+	Bytes int64  `json:"bytes,omitempty"`
+	Total int64  `json:"total,omitempty"`
+}
+
+type progressKey struct{}
+
+func WithProgress(ctx context.Context, report func(progress Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+func ReportProgress(ctx context.Context, progress Progress) {
+	if report, ok := ctx.Value(progressKey{}).(func(Progress)); ok {
+		report(progress)
+	}
+}
+
+func ReportPhase(ctx context.Context, phase string) {
+	ReportProgress(ctx, Progress{Phase: phase})
+}
+
+// reports the number of bytes read so far (at most every 100ms and once at EOF)
+func NewProgressReader(ctx context.Context, reader io.Reader, phase string, total int64) io.Reader {
+	return &progressReader{ctx: ctx, reader: reader, progress: Progress{Phase: phase, Total: max(total, 0)}}
+}
+
+type progressReader struct {
+	ctx      context.Context
+	reader   io.Reader
+	progress Progress
+	last     time.Time
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.reader.Read(p)
+	pr.progress.Bytes += int64(n)
+	if err == io.EOF || time.Since(pr.last) > 100*time.Millisecond {
+		pr.last = time.Now()
+		ReportProgress(pr.ctx, pr.progress)
+	}
+	return n, err
+}
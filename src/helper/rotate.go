@@ -0,0 +1,113 @@
+package helper
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// an append-only log file that is moved to .1, .2, ... once it gets too big,
+// so logs in the data directory can't grow forever
+
+type RotatingFile struct {
+	path    string
+	maxSize int64
+	backups int
+
+	lock sync.Mutex
+	file *os.File
+	size int64
+}
+
+func NewRotatingFile(path string, maxSize int64, backups int) *RotatingFile {
+	return &RotatingFile{path: path, maxSize: maxSize, backups: backups}
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file != nil && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	f.file.Close()
+	f.file = nil
+	for i := f.backups - 1; i > 0; i-- {
+		os.Rename(f.backup(i), f.backup(i+1))
+	}
+	if f.backups > 0 {
+		return os.Rename(f.path, f.backup(1))
+	}
+	return os.Remove(f.path)
+}
+
+func (f *RotatingFile) backup(i int) string {
+	return fmt.Sprintf("%s.%d", f.path, i)
+}
+
+// everything that is still on disk, oldest first
+func (f *RotatingFile) ReadAll() ([]byte, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var buf bytes.Buffer
+	for i := f.backups; i >= 0; i-- {
+		name := f.path
+		if i > 0 {
+			name = f.backup(i)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *RotatingFile) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
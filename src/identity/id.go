@@ -15,6 +15,8 @@ import (
 	"github.com/sag-enhanced/native-app/src/helper"
 )
 
+var ErrInvalidKey = errors.New("invalid private key")
+
 type Identity struct {
 	PrivateKey *rsa.PrivateKey
 }
@@ -68,7 +70,7 @@ func LoadIdentity(fm *file.FileManager) (*Identity, error) {
 		if private, ok := private.(*rsa.PrivateKey); ok {
 			return &Identity{PrivateKey: private}, nil
 		}
-		return nil, errors.New("invalid private key")
+		return nil, ErrInvalidKey
 	}
 	private, err := rsa.GenerateKey(rand.Reader, 4096)
 	if err != nil {
@@ -0,0 +1,182 @@
+package instance
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"time"
+
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// only one app may use a data directory at a time; the first one holds a lock on
+// instance.lock and listens on localhost, later launches hand their arguments over
+// to it (address and token are in instance.json) and exit
+
+var ErrAlreadyRunning = errors.New("another instance is already using this data directory")
+
+const forwardTimeout = 5 * time.Second
+
+type Instance struct {
+	lock     *os.File
+	listener net.Listener
+	token    string
+	info     string
+}
+
+type instanceInfo struct {
+	Pid   int    `json:"pid"`
+	Addr  string `json:"addr"`
+	Token string `json:"token"`
+}
+
+type forwardRequest struct {
+	Token string   `json:"token"`
+	Args  []string `json:"args"`
+}
+
+func Acquire(options *options.Options) (*Instance, error) {
+	lock, err := os.OpenFile(path.Join(options.DataDirectory, "instance.lock"), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		return nil, ErrAlreadyRunning
+	}
+
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		lock.Close()
+		return nil, err
+	}
+
+	instance := &Instance{
+		lock:     lock,
+		listener: listener,
+		token:    base64.RawURLEncoding.EncodeToString(rawToken),
+		info:     path.Join(options.DataDirectory, "instance.json"),
+	}
+	encoded, err := json.Marshal(instanceInfo{Pid: os.Getpid(), Addr: listener.Addr().String(), Token: instance.token})
+	if err == nil {
+		err = os.WriteFile(instance.info, encoded, 0600)
+	}
+	if err != nil {
+		instance.Close()
+		return nil, err
+	}
+	return instance, nil
+}
+
+// only takes the lock, for tools that work on the data directory while the app must not
+// run; later launches of the app fail instead of being handed over
+func Lock(options *options.Options) (release func(), err error) {
+	lock, err := os.OpenFile(path.Join(options.DataDirectory, "instance.lock"), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		return nil, ErrAlreadyRunning
+	}
+	return func() { lock.Close() }, nil
+}
+
+// calls handle with the arguments of every later launch, until the instance is closed
+func (i *Instance) Serve(handle func(args []string)) {
+	for {
+		conn, err := i.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(forwardTimeout))
+			var request forwardRequest
+			if err := json.NewDecoder(conn).Decode(&request); err != nil {
+				return
+			}
+			if subtle.ConstantTimeCompare([]byte(request.Token), []byte(i.token)) != 1 {
+				return
+			}
+			fmt.Fprintln(conn, "ok")
+			handle(request.Args)
+		}()
+	}
+}
+
+func (i *Instance) Close() {
+	i.listener.Close()
+	os.Remove(i.info)
+	// the lock goes away with the file handle
+	i.lock.Close()
+}
+
+// hands the arguments over to the instance that holds the lock
+func Forward(options *options.Options, args []string) error {
+	info, err := readInfo(path.Join(options.DataDirectory, "instance.json"))
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", info.Addr, forwardTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(forwardTimeout))
+	if err := json.NewEncoder(conn).Encode(forwardRequest{Token: info.Token, Args: args}); err != nil {
+		return err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if reply != "ok\n" {
+		return fmt.Errorf("unexpected reply from running instance: %q", reply)
+	}
+	return nil
+}
+
+// the running instance may have just taken the lock and not written its address yet
+func readInfo(filename string) (*instanceInfo, error) {
+	deadline := time.Now().Add(forwardTimeout)
+	for {
+		content, err := os.ReadFile(filename)
+		if err == nil {
+			var info instanceInfo
+			if err = json.Unmarshal(content, &info); err == nil {
+				return &info, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// the pid of the instance holding the data directory, as far as instance.json knows
+func RunningPid(options *options.Options) (int, bool) {
+	content, err := os.ReadFile(path.Join(options.DataDirectory, "instance.json"))
+	if err != nil {
+		return 0, false
+	}
+	var info instanceInfo
+	if err := json.Unmarshal(content, &info); err != nil {
+		return 0, false
+	}
+	return info.Pid, true
+}
@@ -0,0 +1,10 @@
+//go:build !windows && !unix
+
+package instance
+
+import "os"
+
+// no locking available; every launch is the first one
+func lockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,188 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sag-enhanced/native-app/src/helper"
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// every package logs through its own component logger (For("ui"), ...);
+// the level of each component can be set with -log, and everything that passes
+// is written to stderr and to logs/sage.log in the data directory
+
+const (
+	logMaxSize = 5 * 1024 * 1024
+	logBackups = 3
+)
+
+var Components = []string{"app", "ui", "bindings", "browser", "steam", "bundle"}
+
+var levels = map[string]*slog.LevelVar{}
+var levelLock = sync.Mutex{}
+
+// loggers are created at init time, long before Setup knows where to write to
+var output atomic.Pointer[slog.Handler]
+
+func init() {
+	setOutput(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func setOutput(handler slog.Handler) {
+	output.Store(&handler)
+}
+
+func levelOf(component string) *slog.LevelVar {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	level, ok := levels[component]
+	if !ok {
+		level = &slog.LevelVar{}
+		levels[component] = level
+	}
+	return level
+}
+
+func For(component string) *slog.Logger {
+	return slog.New(&componentHandler{
+		level: levelOf(component),
+		wrap: func(handler slog.Handler) slog.Handler {
+			return handler.WithAttrs([]slog.Attr{slog.String("component", component)})
+		},
+	})
+}
+
+// levels are given as "info,browser=debug,steam=warn"; a bare level applies to all components
+func ParseLevels(spec string, verbose bool) (map[string]slog.Level, error) {
+	fallback := slog.LevelInfo
+	if verbose {
+		fallback = slog.LevelDebug
+	}
+	parsed := map[string]slog.Level{}
+	for _, component := range Components {
+		parsed[component] = fallback
+	}
+
+	overrides := map[string]slog.Level{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		component, levelName, found := strings.Cut(part, "=")
+		if !found {
+			component, levelName = "", part
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(levelName)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q", levelName)
+		}
+		if component == "" {
+			for _, component := range Components {
+				parsed[component] = level
+			}
+			continue
+		}
+		if !slices.Contains(Components, component) {
+			return nil, fmt.Errorf("unknown log component %q (expected one of %s)", component, strings.Join(Components, ", "))
+		}
+		overrides[component] = level
+	}
+	// components always win over the bare level, regardless of the order
+	for component, level := range overrides {
+		parsed[component] = level
+	}
+	return parsed, nil
+}
+
+func Setup(options *options.Options) error {
+	parsed, err := ParseLevels(options.LogLevels, options.Verbose)
+	if err != nil {
+		return err
+	}
+	for component, level := range parsed {
+		levelOf(component).Set(level)
+	}
+
+	file := helper.NewRotatingFile(path.Join(options.DataDirectory, "logs", "sage.log"), logMaxSize, logBackups)
+	handlerOptions := &slog.HandlerOptions{Level: slog.LevelDebug}
+	setOutput(multiHandler{
+		slog.NewTextHandler(os.Stderr, handlerOptions),
+		slog.NewJSONHandler(file, handlerOptions),
+	})
+	return nil
+}
+
+type componentHandler struct {
+	level *slog.LevelVar
+	// attributes and groups added to the logger, applied to whatever the output is at the time
+	wrap func(slog.Handler) slog.Handler
+}
+
+func (h *componentHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *componentHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.wrap(*output.Load()).Handle(ctx, record)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &componentHandler{level: h.level, wrap: func(handler slog.Handler) slog.Handler {
+		return h.wrap(handler).WithAttrs(attrs)
+	}}
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return &componentHandler{level: h.level, wrap: func(handler slog.Handler) slog.Handler {
+		return h.wrap(handler).WithGroup(name)
+	}}
+}
+
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range m {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range m {
+		if handler.Enabled(ctx, record.Level) {
+			if err := handler.Handle(ctx, record.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, handler := range m {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, handler := range m {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// output of child processes (browsers, the playwright driver, ...) is logged line by line,
+// tagged with the stream it came from
+
+const streamMaxLine = 64 * 1024
+
+type streamWriter struct {
+	logger *slog.Logger
+	stream string
+
+	lock sync.Mutex
+	buf  []byte
+}
+
+func Stream(logger *slog.Logger, stream string) io.Writer {
+	return &streamWriter{logger: logger, stream: stream}
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	// don't buffer forever if something never writes a newline
+	if len(w.buf) > streamMaxLine {
+		w.log(w.buf)
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+func (w *streamWriter) log(line []byte) {
+	text := strings.TrimRight(string(line), "\r")
+	if text != "" {
+		w.logger.Info(text, "stream", w.stream)
+	}
+}
@@ -3,6 +3,9 @@ package options
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"path"
+
+	"github.com/sag-enhanced/native-app/src/deeplink"
 )
 
 type Options struct {
@@ -10,15 +13,29 @@ type Options struct {
 	Release      uint32
 	LoopbackPort uint16
 
-	Verbose         bool
-	Realm           Realm
+	Verbose   bool
+	LogLevels string
+	Realm     Realm
+	// what Realm resolved to, see LoadRealm
+	RealmDescriptor *RealmDescriptor
 	OpenCommand     []string
 	UI              UI
+	UISocket        string
 	SteamDev        bool
 	DataDirectory   string
 	NoCompress      bool
 	ForceBrowser    string
+	LockOnExit      bool
 	ProxyBypassList string
+	NoRegister      bool
+	// closing the window hides it in the tray instead of quitting
+	CloseToTray bool
+	// the playwright UI runs chromium without a window
+	PlaywrightHeadless bool
+	// the playwright UI records a trace and a HAR into the data directory
+	PlaywrightTrace bool
+	// the deep link the app was launched with, if any
+	DeepLink *deeplink.Link
 
 	CurrentUrlSecret string
 }
@@ -27,6 +44,7 @@ func NewOptions() *Options {
 	secret := make([]byte, 32)
 	rand.Read(secret) // let's pray this doesn't fail
 
+	dataDirectory := GetDefaultStoragePath()
 	return &Options{
 		Build:        15,
 		Release:      4,
@@ -34,7 +52,8 @@ func NewOptions() *Options {
 
 		UI:            GetPreferredUI(),
 		OpenCommand:   GetDefaultOpenCommand(),
-		DataDirectory: GetDefaultStoragePath(),
+		DataDirectory: dataDirectory,
+		UISocket:      path.Join(dataDirectory, "sage.sock"),
 
 		CurrentUrlSecret: base64.RawURLEncoding.EncodeToString(secret),
 	}
@@ -1,5 +1,23 @@
 package options
 
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sag-enhanced/native-app/src/deeplink"
+)
+
 type Realm = string
 
 const (
@@ -9,16 +27,215 @@ const (
 	LocalRealm  Realm = "local"
 )
 
+// everything that decides which sites the app trusts; -realm is either one of the names
+// above, the name of a file in realms/ in the data directory (without .json), a path to
+// a .json file, or (for compatibility) a host that is served over https
+type RealmDescriptor struct {
+	// the frontend, which may call all bindings
+	App string `json:"app"`
+	// where connect intents are approved
+	Identity string `json:"identity"`
+	// may be shown in the window, but may only call the bindings every page can
+	AllowedOrigins []string `json:"allowedOrigins"`
+	// hosts the browsers reach without the proxy, unless -proxybypasslist says otherwise
+	ProxyBypass []string `json:"proxyBypass"`
+	// base64 PKIX key that signs connect intents (ES256)
+	ConnectKey string `json:"connectKey"`
+	// a signed bundle (zip or directory, relative to the realm file) to serve the frontend
+	// from instead of loading it from app
+	Bundle string `json:"bundle,omitempty"`
+	// the app as configured, once a bundle took its place
+	RemoteApp string `json:"-"`
+}
+
+const defaultIdentityOrigin = "https://id.sage.party"
+
+// trust me, this is required to keep confidentiality in case of id.sage.party compromise
+const defaultConnectKey = "MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE2e1f7yahniHxwrVhs8XKVKdo7wHHSPwfMov16RyNiodfh/yoJjZ/5Tz1TdPAhcFWqrFkcSIzIJfQURwc1fVntA=="
+
+var defaultProxyBypass = []string{
+	// SAGE
+	"*.sage.party",
+	"*.leodev.cloud",
+	"*.sagemail.top",
+
+	// Brave browser
+	"*.brave.com",
+	"*.brave-http-only.com",
+
+	// Captcha solvers
+	"*.nopecha.com",
+}
+
+var builtinRealms = map[Realm]string{
+	StableRealm: "https://app.sage.party",
+	BetaRealm:   "https://app-beta.sage.party",
+	DevRealm:    "https://app-dev.sage.party",
+	LocalRealm:  "http://localhost:5173",
+}
+
+var ErrInvalidRealm = errors.New("invalid realm")
+
+func defaultRealm(app string) *RealmDescriptor {
+	return &RealmDescriptor{
+		App:            app,
+		Identity:       defaultIdentityOrigin,
+		AllowedOrigins: []string{},
+		ProxyBypass:    defaultProxyBypass,
+		ConnectKey:     defaultConnectKey,
+	}
+}
+
+// resolves -realm; has to be called once the flags and the config are in
+func (options *Options) LoadRealm() error {
+	realm, err := loadRealm(options.Realm, options.DataDirectory)
+	if err != nil {
+		return err
+	}
+	options.RealmDescriptor = realm
+	if options.ProxyBypassList == "" {
+		options.ProxyBypassList = strings.Join(realm.ProxyBypass, ";")
+	}
+	return nil
+}
+
+func loadRealm(name string, dataDirectory string) (*RealmDescriptor, error) {
+	if app, ok := builtinRealms[name]; ok {
+		return defaultRealm(app), nil
+	}
+
+	filename := name
+	if !strings.HasSuffix(name, ".json") {
+		if strings.ContainsAny(name, `/\`) {
+			return defaultRealm("https://" + name), nil
+		}
+		filename = path.Join(dataDirectory, "realms", name+".json")
+	}
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) && filename != name {
+		return defaultRealm("https://" + name), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	realm := &RealmDescriptor{}
+	if err := json.Unmarshal(content, realm); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if err := realm.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if realm.Bundle != "" && !filepath.IsAbs(realm.Bundle) {
+		realm.Bundle = filepath.Join(filepath.Dir(filename), realm.Bundle)
+	}
+	return realm, nil
+}
+
+func (realm *RealmDescriptor) validate() error {
+	var err error
+	if realm.App, err = normalizeOrigin(realm.App); err != nil {
+		return fmt.Errorf("%w: \"app\": %w", ErrInvalidRealm, err)
+	}
+	if realm.Identity == "" {
+		realm.Identity = defaultIdentityOrigin
+	}
+	if realm.Identity, err = normalizeOrigin(realm.Identity); err != nil {
+		return fmt.Errorf("%w: \"identity\": %w", ErrInvalidRealm, err)
+	}
+	if realm.AllowedOrigins == nil {
+		realm.AllowedOrigins = []string{}
+	}
+	for i, origin := range realm.AllowedOrigins {
+		if realm.AllowedOrigins[i], err = normalizeOrigin(origin); err != nil {
+			return fmt.Errorf("%w: \"allowedOrigins\": %w", ErrInvalidRealm, err)
+		}
+	}
+	// an empty list is a choice, a missing one means the defaults
+	if realm.ProxyBypass == nil {
+		realm.ProxyBypass = defaultProxyBypass
+	}
+	if realm.ConnectKey == "" {
+		realm.ConnectKey = defaultConnectKey
+	}
+	if _, err := realm.ParseConnectKey(); err != nil {
+		return fmt.Errorf("%w: \"connectKey\": %w", ErrInvalidRealm, err)
+	}
+	return nil
+}
+
+// only scheme and host (as the browser reports location.origin); plain http is for
+// development on this machine only
+func normalizeOrigin(origin string) (string, error) {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" || u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("%q is not an origin (like https://example.com)", origin)
+	}
+	if u.Scheme == "http" {
+		if ip := net.ParseIP(u.Hostname()); u.Hostname() != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return "", fmt.Errorf("%q: http is only allowed for localhost", origin)
+		}
+	} else if u.Scheme != "https" {
+		return "", fmt.Errorf("%q: must be https", origin)
+	}
+	return u.Scheme + "://" + strings.ToLower(u.Host), nil
+}
+
+func (realm *RealmDescriptor) ParseConnectKey() (*ecdsa.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(realm.ConnectKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		return nil, errors.New("must be a P-256 public key")
+	}
+	return ecKey, nil
+}
+
+// the descriptor -realm resolved to; before LoadRealm only the built-in realms are known
+func (options *Options) GetRealm() *RealmDescriptor {
+	if options.RealmDescriptor != nil {
+		return options.RealmDescriptor
+	}
+	if app, ok := builtinRealms[options.Realm]; ok {
+		return defaultRealm(app)
+	}
+	return defaultRealm("https://" + options.Realm)
+}
+
 func (options *Options) GetRealmOrigin() string {
-	switch options.Realm {
-	case StableRealm:
-		return "https://app.sage.party"
-	case BetaRealm:
-		return "https://app-beta.sage.party"
-	case DevRealm:
-		return "https://app-dev.sage.party"
-	case LocalRealm:
-		return "http://localhost:5173"
-	}
-	return "https://" + options.Realm
+	return options.GetRealm().App
+}
+
+// where the realm is published, even while the frontend comes from a bundle; bundles are
+// made for and cached by this origin
+func (options *Options) GetRemoteRealmOrigin() string {
+	realm := options.GetRealm()
+	if realm.RemoteApp != "" {
+		return realm.RemoteApp
+	}
+	return realm.App
+}
+
+func (options *Options) GetIdentityOrigin() string {
+	return options.GetRealm().Identity
+}
+
+// origins that may be shown in the window at all
+func (options *Options) GetTrustedOrigins() []string {
+	realm := options.GetRealm()
+	return append([]string{realm.App, realm.Identity}, realm.AllowedOrigins...)
+}
+
+// where the page starts out, which is somewhere else in the realm if the app was launched by a link
+func (options *Options) GetStartUrl() string {
+	if options.DeepLink != nil && options.DeepLink.Action == deeplink.ActionOpen {
+		return options.DeepLink.Target(options.GetRealmOrigin())
+	}
+	return options.GetRealmOrigin()
 }
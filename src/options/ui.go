@@ -5,10 +5,13 @@ type UI = string
 const (
 	PlaywrightUI UI = "playwright"
 	WebviewUI    UI = "webview"
+	// headless JSON-RPC transports (for automation and tests)
+	StdioUI  UI = "stdio"
+	SocketUI UI = "socket"
 )
 
 func GetPreferredUI() UI {
-	if isWebviewAvailable() {
+	if IsWebviewAvailable() {
 		return WebviewUI
 	}
 	return PlaywrightUI
@@ -2,6 +2,6 @@
 
 package options
 
-func isWebviewAvailable() bool {
+func IsWebviewAvailable() bool {
 	return true
 }
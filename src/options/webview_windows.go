@@ -4,7 +4,7 @@ package options
 
 import "golang.org/x/sys/windows/registry"
 
-func isWebviewAvailable() bool {
+func IsWebviewAvailable() bool {
 	// https://learn.microsoft.com/en-us/microsoft-edge/webview2/concepts/distribution?tabs=dotnetcsharp#detect-if-a-webview2-runtime-is-already-installed
 	for _, edge := range []edgeLocation{
 		{registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Microsoft\EdgeUpdate\Clients\{F3017226-FE2A-4295-8BDF-00C3A9A7E4C5}`},
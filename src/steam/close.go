@@ -1,14 +1,15 @@
 package steam
 
 import (
-	"fmt"
+	"context"
 	"time"
 
+	"github.com/sag-enhanced/native-app/src/helper"
 	"github.com/sag-enhanced/native-app/src/options"
 	"github.com/shirou/gopsutil/v3/process"
 )
 
-func CloseSteam(options *options.Options) error {
+func CloseSteam(ctx context.Context, options *options.Options) error {
 	killed := int32(0)
 	for {
 		var proc *process.Process
@@ -18,17 +19,18 @@ func CloseSteam(options *options.Options) error {
 		}
 		if proc.Pid != killed {
 			// new process found (this can happen if we close steam while its still bootstrapping)
-			if options.Verbose {
-				fmt.Println("Steam running, shutting it down...")
-			}
+			logger.Debug("Steam running, shutting it down")
 
 			RunSteamWithArguments(options, "-shutdown")
 			killed = proc.Pid
 		}
-		if options.Verbose {
-			fmt.Println("Waiting for Steam to shut down...", proc.Pid)
+		logger.Debug("Waiting for Steam to shut down", "pid", proc.Pid)
+		helper.ReportPhase(ctx, "steam-shutdown")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(1 * time.Second):
 		}
-		time.Sleep(1 * time.Second)
 	}
 	return nil
 }
@@ -2,7 +2,6 @@ package steam
 
 import (
 	"errors"
-	"fmt"
 	"os"
 	"path"
 	"path/filepath"
@@ -10,10 +9,18 @@ import (
 	"time"
 
 	"github.com/sag-enhanced/native-app/src/helper"
+	"github.com/sag-enhanced/native-app/src/logging"
 	"github.com/sag-enhanced/native-app/src/options"
 	"github.com/shirou/gopsutil/v3/process"
 )
 
+var (
+	ErrSteamNotFound     = errors.New("Steam process not found")
+	ErrSteamDataNotFound = errors.New("Steam data directory not found")
+)
+
+var logger = logging.For("steam")
+
 // looking where the steam executable is from currently running processes
 // seemed like the most reliable way to find it on all platforms
 func FindSteamExecutable(options *options.Options) (string, error) {
@@ -26,10 +33,10 @@ func FindSteamExecutable(options *options.Options) (string, error) {
 		return string(data), nil
 	}
 
-	fmt.Println("Searching for Steam executable...")
+	logger.Info("Searching for Steam executable")
 	process, err := findSteamProcess()
 	if err != nil {
-		fmt.Println("Steam process not found; starting it...")
+		logger.Info("Steam process not found; starting it")
 		// we are opening steam now
 		// opening the console just for why not
 		// the code that is calling this will close steam immediately afterwards anyway
@@ -38,11 +45,11 @@ func FindSteamExecutable(options *options.Options) (string, error) {
 			if process, err = findSteamProcess(); err != nil {
 				break
 			}
-			fmt.Println("Waiting for Steam process...")
+			logger.Info("Waiting for Steam process")
 			time.Sleep(1 * time.Second)
 		}
 	}
-	fmt.Println("Steam process found: ", process.Pid)
+	logger.Info("Steam process found", "pid", process.Pid)
 	exe, err := process.Exe()
 	if err != nil {
 		return "", err
@@ -52,6 +59,18 @@ func FindSteamExecutable(options *options.Options) (string, error) {
 	return exe, nil
 }
 
+// like FindSteamExecutable, but without starting Steam if it isn't known yet
+func LocateSteamExecutable(options *options.Options) (string, error) {
+	if data, err := os.ReadFile(path.Join(options.DataDirectory, "steam_executable.txt")); err == nil {
+		return string(data), nil
+	}
+	process, err := findSteamProcess()
+	if err != nil {
+		return "", err
+	}
+	return process.Exe()
+}
+
 func FindSteamDataDir(options *options.Options) (string, error) {
 	if runtime.GOOS == "darwin" {
 		// the application in /Applications is just the bootstrapper, the real executable
@@ -71,7 +90,7 @@ func FindSteamDataDir(options *options.Options) (string, error) {
 		parent = filepath.Dir(parent)
 	}
 	if len(parent) <= 1 {
-		return "", errors.New("Steam data directory not found")
+		return "", ErrSteamDataNotFound
 	}
 	return parent, nil
 }
@@ -90,5 +109,5 @@ func findSteamProcess() (*process.Process, error) {
 			return p, nil
 		}
 	}
-	return nil, errors.New("Steam process not found")
+	return nil, ErrSteamNotFound
 }
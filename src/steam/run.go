@@ -1,9 +1,9 @@
 package steam
 
 import (
-	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"runtime"
 
 	"github.com/sag-enhanced/native-app/src/options"
@@ -27,13 +27,19 @@ func RunSteamWithArguments(options *options.Options, args ...string) error {
 		args = append(args, "-dev")
 	}
 
-	if options.Verbose {
-		fmt.Println("Running Steam with arguments:", executable, args)
-	}
+	logger.Debug("Running Steam", "executable", executable, "args", args)
 	cmd := exec.Command(executable, args...)
-	// steam is dying without having stdout attached
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// steam is dying without having stdout attached, and it outlives us, so its output
+	// can't go through a pipe into our log (it would die as soon as we exit); it gets a file instead
+	logDirectory := path.Join(options.DataDirectory, "logs")
+	os.MkdirAll(logDirectory, 0755)
+	output, err := os.OpenFile(path.Join(logDirectory, "steam.log"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	cmd.Stdout = output
+	cmd.Stderr = output
 
 	if runtime.GOOS == "windows" {
 		// so windows apparently doesnt have the bootstrapper and it will directly start steam
@@ -0,0 +1,18 @@
+package tray
+
+// the tray keeps the app reachable while its window is hidden (-closetotray); it only
+// exists with the webview UI, which runs the native main loop it needs
+
+// the menu and what it does; every action is also emitted to the page as a saget event,
+// with the action and its argument
+type Actions struct {
+	Show func()
+	// forgets the encryption key
+	Lock    func()
+	CanLock func() bool
+	// handles of the running browsers
+	Browsers    func() []string
+	KillBrowser func(handle string)
+	Quit        func()
+	Emit        func(event string, args ...any)
+}
@@ -0,0 +1,122 @@
+//go:build windows || linux || (darwin && cgo)
+
+package tray
+
+import (
+	_ "embed"
+	"runtime"
+	"slices"
+	"time"
+
+	"fyne.io/systray"
+	"github.com/sag-enhanced/native-app/src/logging"
+)
+
+var logger = logging.For("ui")
+
+//go:embed icon.png
+var iconPng []byte
+
+//go:embed icon.ico
+var iconIco []byte
+
+// the menu follows the app (browsers, encryption) at this pace
+const refreshInterval = 2 * time.Second
+
+// must be called on the UI thread; the returned function removes the icon again
+func Start(actions Actions) (stop func()) {
+	done := make(chan struct{})
+	// the end function would also close the DBus connection, which isn't there if the
+	// session has no bus; the process is about to exit anyway
+	start, _ := systray.RunWithExternalLoop(func() { build(actions, done) }, nil)
+	start()
+	return func() {
+		close(done)
+		systray.Quit()
+	}
+}
+
+func build(actions Actions, done chan struct{}) {
+	if runtime.GOOS == "windows" {
+		systray.SetIcon(iconIco)
+	} else {
+		systray.SetIcon(iconPng)
+	}
+	systray.SetTooltip("SAG Enhanced")
+	// a left click shows the window, the menu is on the right click
+	systray.SetOnTapped(func() { act(actions, "show", nil, actions.Show) })
+
+	show := systray.AddMenuItem("Show SAGE", "")
+	lock := systray.AddMenuItem("Lock storage", "Forget the encryption key until the password is entered again")
+	browsers := systray.AddMenuItem("Browsers", "")
+	systray.AddSeparator()
+	quit := systray.AddMenuItem("Quit", "")
+
+	onClick(show, func() { act(actions, "show", nil, actions.Show) })
+	onClick(lock, func() { act(actions, "lock", nil, actions.Lock) })
+	onClick(quit, func() { act(actions, "quit", nil, actions.Quit) })
+
+	var running []string
+	var items []*systray.MenuItem
+	refresh := func() {
+		if actions.CanLock() {
+			lock.Enable()
+		} else {
+			lock.Disable()
+		}
+
+		current := actions.Browsers()
+		if slices.Equal(current, running) && items != nil {
+			return
+		}
+		running = current
+		for _, item := range items {
+			item.Remove()
+		}
+		items = nil
+		if len(running) == 0 {
+			none := browsers.AddSubMenuItem("No browsers running", "")
+			none.Disable()
+			items = append(items, none)
+			return
+		}
+		for _, handle := range running {
+			item := browsers.AddSubMenuItem("Close browser "+handle[:8], "")
+			onClick(item, func() {
+				act(actions, "kill", handle, func() { actions.KillBrowser(handle) })
+			})
+			items = append(items, item)
+		}
+	}
+
+	refresh()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
+
+func onClick(item *systray.MenuItem, fn func()) {
+	go func() {
+		// closed when the item is removed
+		for range item.ClickedCh {
+			fn()
+		}
+	}()
+}
+
+func act(actions Actions, action string, argument any, fn func()) {
+	logger.Debug("Tray action", "action", action)
+	if argument != nil {
+		actions.Emit("saget", action, argument)
+	} else {
+		actions.Emit("saget", action)
+	}
+	fn()
+}
@@ -0,0 +1,8 @@
+//go:build !(windows || linux || (darwin && cgo))
+
+package tray
+
+// no tray on this platform
+func Start(actions Actions) (stop func()) {
+	return func() {}
+}
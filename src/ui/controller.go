@@ -0,0 +1,35 @@
+//go:build cgo
+
+package ui
+
+/*
+#include <stdlib.h>
+
+// part of webview, which the Go package doesn't wrap
+extern void *webview_get_native_handle(void *w, int kind);
+*/
+import "C"
+import (
+	"reflect"
+	"unsafe"
+
+	webview_go "github.com/sag-enhanced/webview_go"
+)
+
+// WEBVIEW_NATIVE_HANDLE_KIND_BROWSER_CONTROLLER
+const browserControllerKind = 2
+
+// the WebKitWebView (Linux), WKWebView (macOS) or ICoreWebView2Controller (Windows) of the
+// view, or nil if it can't be reached. webview_go keeps the webview_t in the only field of
+// the value behind WebView
+func browserController(view webview_go.WebView) unsafe.Pointer {
+	value := reflect.ValueOf(view)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct || value.Elem().NumField() != 1 {
+		return nil
+	}
+	field := value.Elem().Field(0)
+	if field.Kind() != reflect.UnsafePointer || field.UnsafePointer() == nil {
+		return nil
+	}
+	return C.webview_get_native_handle(field.UnsafePointer(), browserControllerKind)
+}
@@ -0,0 +1,13 @@
+//go:build !cgo
+
+package ui
+
+import (
+	"unsafe"
+
+	webview_go "github.com/sag-enhanced/webview_go"
+)
+
+func browserController(view webview_go.WebView) unsafe.Pointer {
+	return nil
+}
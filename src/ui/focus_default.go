@@ -0,0 +1,8 @@
+//go:build !windows && !(linux && cgo)
+
+package ui
+
+import "unsafe"
+
+// no native way to bring the window to the front on this platform (yet)
+func focusWindow(window unsafe.Pointer) {}
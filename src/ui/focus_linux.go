@@ -0,0 +1,19 @@
+//go:build linux && cgo
+
+package ui
+
+/*
+#cgo pkg-config: gtk+-3.0
+#include <gtk/gtk.h>
+
+static void present_window(void *window) {
+	gtk_window_present(GTK_WINDOW(window));
+}
+*/
+import "C"
+import "unsafe"
+
+// window is the GtkWindow of the webview; must be called on the UI thread
+func focusWindow(window unsafe.Pointer) {
+	C.present_window(window)
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32                  = windows.NewLazySystemDLL("user32.dll")
+	procIsIconic            = user32.NewProc("IsIconic")
+	procShowWindow          = user32.NewProc("ShowWindow")
+	procSetForegroundWindow = user32.NewProc("SetForegroundWindow")
+)
+
+const (
+	swShow    = 5
+	swRestore = 9
+)
+
+// window is the HWND of the webview; must be called on the UI thread
+func focusWindow(window unsafe.Pointer) {
+	hwnd := uintptr(window)
+	// hidden in the tray
+	if visible, _, _ := procIsWindowVisible.Call(hwnd); visible == 0 {
+		procShowWindow.Call(hwnd, swShow)
+	}
+	if minimized, _, _ := procIsIconic.Call(hwnd); minimized != 0 {
+		procShowWindow.Call(hwnd, swRestore)
+	}
+	procSetForegroundWindow.Call(hwnd)
+}
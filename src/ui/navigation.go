@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/sag-enhanced/native-app/src/helper"
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// the window may only show the realm (and the origins it trusts); everything else is
+// stopped natively where the UI allows it, on top of the redirect in the init script.
+// links to other sites are opened in the default browser instead
+
+// what happened to a navigation that left the realm
+const (
+	NavigationBlocked = "blocked"
+	// opened in the default browser instead
+	NavigationOpened = "opened"
+)
+
+// a page navigating over and over must not flood the default browser
+const openInterval = time.Second
+
+// window is the name of the window the navigation happened in
+type navigationHandler func(window string, url string, outcome string)
+
+var lastOpened time.Time
+var openLock = sync.Mutex{}
+
+func allowedNavigation(opt *options.Options, rawUrl string) bool {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "about":
+		return u.Opaque == "blank"
+	case "blob":
+		// blob URLs carry the origin that created them
+		if u, err = url.Parse(u.Opaque); err != nil {
+			return false
+		}
+	}
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+	return slices.ContainsFunc(opt.GetTrustedOrigins(), func(trusted string) bool {
+		return strings.ToLower(trusted) == origin
+	})
+}
+
+// for navigations that aren't allowed, after they were stopped
+func blockNavigation(opt *options.Options, handler navigationHandler, window string, rawUrl string) {
+	outcome := NavigationBlocked
+	if u, err := url.Parse(rawUrl); err == nil && (u.Scheme == "https" || u.Scheme == "http") {
+		openLock.Lock()
+		if time.Since(lastOpened) >= openInterval {
+			lastOpened = time.Now()
+			outcome = NavigationOpened
+		}
+		openLock.Unlock()
+	}
+	logger.Warn("Navigation outside of the realm", "window", window, "url", rawUrl, "outcome", outcome)
+	if outcome == NavigationOpened {
+		go helper.Open(rawUrl, opt)
+	}
+	if handler != nil {
+		handler(window, rawUrl, outcome)
+	}
+}
+
+// the checks of the guarded web views, by their browser controller
+var navigationChecks = map[unsafe.Pointer]func(url string) bool{}
+var navigationLock = sync.Mutex{}
+
+// browser is what browserController returns, check says whether the window may go to url;
+// on the UI thread
+func guardNavigation(browser unsafe.Pointer, check func(url string) bool) error {
+	if browser == nil {
+		return errors.New("no browser controller")
+	}
+	navigationLock.Lock()
+	navigationChecks[browser] = check
+	navigationLock.Unlock()
+	if err := hookNavigation(browser); err != nil {
+		unguardNavigation(browser)
+		return err
+	}
+	return nil
+}
+
+// on the UI thread, before the web view is destroyed
+func unguardNavigation(browser unsafe.Pointer) {
+	if browser == nil {
+		return
+	}
+	unhookNavigation(browser)
+	navigationLock.Lock()
+	defer navigationLock.Unlock()
+	delete(navigationChecks, browser)
+}
+
+// for the native guards; web views that aren't guarded (anymore) may go anywhere
+func navigationAllowed(browser unsafe.Pointer, url string) bool {
+	navigationLock.Lock()
+	check, ok := navigationChecks[browser]
+	navigationLock.Unlock()
+	return !ok || check(url)
+}
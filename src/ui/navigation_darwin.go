@@ -0,0 +1,59 @@
+//go:build darwin && cgo
+
+package ui
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework WebKit -framework Foundation
+#import <WebKit/WebKit.h>
+
+extern int sageNavigationAllowed(void *browser, char *uri);
+
+@interface SageNavigationDelegate : NSObject <WKNavigationDelegate>
+@end
+
+@implementation SageNavigationDelegate
+// decided before the request is sent; new windows have no target frame and are checked too
+- (void)webView:(WKWebView *)webView decidePolicyForNavigationAction:(WKNavigationAction *)action decisionHandler:(void (^)(WKNavigationActionPolicy))decisionHandler {
+	NSString *uri = action.request.URL.absoluteString;
+	BOOL mainFrame = action.targetFrame == nil || action.targetFrame.isMainFrame;
+	if (mainFrame && uri != nil && !sageNavigationAllowed((__bridge void *)webView, (char *)uri.UTF8String)) {
+		decisionHandler(WKNavigationActionPolicyCancel);
+		return;
+	}
+	decisionHandler(WKNavigationActionPolicyAllow);
+}
+@end
+
+// the web view doesn't keep its delegate alive
+static SageNavigationDelegate *navigationDelegate;
+
+static int navigation_hook(void *browser) {
+	id view = (__bridge id)browser;
+	if (![view isKindOfClass:[WKWebView class]]) {
+		return 0;
+	}
+	if (navigationDelegate == nil) {
+		navigationDelegate = [SageNavigationDelegate new];
+	}
+	((WKWebView *)view).navigationDelegate = navigationDelegate;
+	return 1;
+}
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// iframes are left alone. a cancelled navigation leaves the page where it was
+
+func hookNavigation(browser unsafe.Pointer) error {
+	if C.navigation_hook(browser) == 0 {
+		return errors.New("the browser controller isn't a web view")
+	}
+	return nil
+}
+
+// the delegate goes with the web view
+func unhookNavigation(browser unsafe.Pointer) {}
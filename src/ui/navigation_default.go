@@ -0,0 +1,17 @@
+//go:build !windows && !((linux || darwin) && cgo)
+
+package ui
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// webview doesn't give us its navigation events here; the page reporting its URL is
+// checked instead, see WebviewUII.checkUrl
+
+func hookNavigation(browser unsafe.Pointer) error {
+	return errors.ErrUnsupported
+}
+
+func unhookNavigation(browser unsafe.Pointer) {}
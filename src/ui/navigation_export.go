@@ -0,0 +1,16 @@
+//go:build (linux || darwin) && cgo
+
+package ui
+
+// separate from the guards, a file with exports must not define any C functions
+
+import "C"
+import "unsafe"
+
+//export sageNavigationAllowed
+func sageNavigationAllowed(browser unsafe.Pointer, uri *C.char) C.int {
+	if navigationAllowed(browser, C.GoString(uri)) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,50 @@
+//go:build linux && cgo
+
+package ui
+
+/*
+#cgo pkg-config: gtk+-3.0 webkit2gtk-4.1
+#include <webkit2/webkit2.h>
+
+extern int sageNavigationAllowed(void *browser, char *uri);
+
+// decided before the request is sent; redirects are decided too
+static gboolean navigation_decide(WebKitWebView *webview, WebKitPolicyDecision *decision, WebKitPolicyDecisionType type, gpointer data) {
+	if (type != WEBKIT_POLICY_DECISION_TYPE_NAVIGATION_ACTION) {
+		return FALSE;
+	}
+	WebKitNavigationAction *action = webkit_navigation_policy_decision_get_navigation_action(WEBKIT_NAVIGATION_POLICY_DECISION(decision));
+	const gchar *uri = webkit_uri_request_get_uri(webkit_navigation_action_get_request(action));
+	if (uri != NULL && !sageNavigationAllowed(webview, (char *)uri)) {
+		webkit_policy_decision_ignore(decision);
+		return TRUE;
+	}
+	return FALSE;
+}
+
+static int navigation_hook(void *browser) {
+	if (!WEBKIT_IS_WEB_VIEW(browser)) {
+		return 0;
+	}
+	g_signal_connect(G_OBJECT(browser), "decide-policy", G_CALLBACK(navigation_decide), NULL);
+	return 1;
+}
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// navigation actions are only decided for the main frame, so iframes are left alone. an
+// ignored navigation leaves the page where it was
+
+func hookNavigation(browser unsafe.Pointer) error {
+	if C.navigation_hook(browser) == 0 {
+		return errors.New("the browser controller isn't a web view")
+	}
+	return nil
+}
+
+// the signal goes with the web view
+func unhookNavigation(browser unsafe.Pointer) {}
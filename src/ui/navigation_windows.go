@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// WebView2 is COM: every object starts with a pointer to its table of methods, the first
+// three being IUnknown's. these are the indices of the methods used here
+const (
+	comRelease                   = 2
+	controllerGetCoreWebView2    = 25
+	coreWebViewAddNavigation     = 7
+	coreWebViewRemoveNavigation  = 8
+	navigationArgsGetUri         = 3
+	navigationArgsPutCancel      = 8
+	comNoInterface               = 0x80004002
+	iidUnknown                   = "{00000000-0000-0000-C000-000000000046}"
+	iidNavigationStartingHandler = "{9ADBE429-F36D-432B-9DDC-F8881FBD76E3}"
+)
+
+var (
+	ole32             = windows.NewLazySystemDLL("ole32.dll")
+	procCoTaskMemFree = ole32.NewProc("CoTaskMemFree")
+)
+
+func comCall(object unsafe.Pointer, method int, args ...uintptr) uintptr {
+	vtable := *(**[32]uintptr)(object)
+	result, _, _ := syscall.SyscallN(vtable[method], append([]uintptr{uintptr(object)}, args...)...)
+	return result
+}
+
+// an ICoreWebView2NavigationStartingEventHandler, kept in navigationHandlers while WebView2
+// holds on to it
+type navigationStartingHandler struct {
+	vtable  *[4]uintptr
+	browser unsafe.Pointer
+	core    unsafe.Pointer
+	token   int64
+}
+
+var navigationHandlers = map[unsafe.Pointer]*navigationStartingHandler{}
+
+var navigationHandlerVtable = [4]uintptr{
+	windows.NewCallback(func(this uintptr, iid *windows.GUID, object *uintptr) uintptr {
+		if name := iid.String(); name != iidUnknown && name != iidNavigationStartingHandler {
+			*object = 0
+			return comNoInterface
+		}
+		*object = this
+		return 0
+	}),
+	// the handler lives as long as the guard, not as long as its references
+	windows.NewCallback(func(this uintptr) uintptr { return 1 }),
+	windows.NewCallback(func(this uintptr) uintptr { return 1 }),
+	// NavigationStarting is only raised for the main frame, before the request is sent;
+	// redirects raise it again
+	windows.NewCallback(func(this *navigationStartingHandler, sender uintptr, args unsafe.Pointer) uintptr {
+		var uri *uint16
+		if comCall(args, navigationArgsGetUri, uintptr(unsafe.Pointer(&uri))) != 0 || uri == nil {
+			return 0
+		}
+		defer procCoTaskMemFree.Call(uintptr(unsafe.Pointer(uri)))
+		if !navigationAllowed(this.browser, windows.UTF16PtrToString(uri)) {
+			comCall(args, navigationArgsPutCancel, 1)
+		}
+		return 0
+	}),
+}
+
+// iframes are left alone. a cancelled navigation leaves the page where it was
+func hookNavigation(browser unsafe.Pointer) error {
+	handler := &navigationStartingHandler{vtable: &navigationHandlerVtable, browser: browser}
+	if comCall(browser, controllerGetCoreWebView2, uintptr(unsafe.Pointer(&handler.core))) != 0 || handler.core == nil {
+		return errors.New("the browser controller has no web view")
+	}
+	if result := comCall(handler.core, coreWebViewAddNavigation, uintptr(unsafe.Pointer(handler)), uintptr(unsafe.Pointer(&handler.token))); result != 0 {
+		comCall(handler.core, comRelease)
+		return windows.Errno(result)
+	}
+	navigationLock.Lock()
+	defer navigationLock.Unlock()
+	navigationHandlers[browser] = handler
+	return nil
+}
+
+func unhookNavigation(browser unsafe.Pointer) {
+	navigationLock.Lock()
+	handler, ok := navigationHandlers[browser]
+	delete(navigationHandlers, browser)
+	navigationLock.Unlock()
+	if ok {
+		comCall(handler.core, coreWebViewRemoveNavigation, uintptr(handler.token))
+		comCall(handler.core, comRelease)
+	}
+}
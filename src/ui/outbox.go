@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// results, progress and events for the page are queued here and delivered
+// as one Eval per frame, instead of one dispatch to the UI thread per message
+// (which floods it when a lot of requests and loopback traffic are going on).
+// a batch counts against outboxMaxBytes until the page actually ran it, and the
+// next one only goes out after that. the UI thread itself never waits for space
+// (see post), it is what makes room
+
+const (
+	outboxFrame = 16 * time.Millisecond
+	// once this much is pending, progress gets dropped and everything else has to wait
+	outboxMaxBytes = 8 * 1024 * 1024
+	outboxLate     = 1 * time.Second
+)
+
+// a page that never finishes running a batch (a hung renderer, a promise that never
+// settles) must not hold up delivery for good; a var so the tests don't have to wait
+var outboxDeliveryTimeout = 10 * time.Second
+
+type outboxMessage struct {
+	script string
+	queued time.Time
+	// progress is superseded by the next progress event anyway
+	progressOf int
+}
+
+type outbox struct {
+	// done is called once the script ran (or never will)
+	eval func(script string, done func())
+
+	lock    sync.Mutex
+	space   *sync.Cond
+	pending []outboxMessage
+	size    int
+	dropped int
+	wake    chan struct{}
+	// the page is gone, there is nothing to deliver to anymore
+	closed  bool
+	closing chan struct{}
+}
+
+func newOutbox(eval func(script string, done func())) *outbox {
+	o := &outbox{
+		eval:    eval,
+		wake:    make(chan struct{}, 1),
+		closing: make(chan struct{}),
+	}
+	o.space = sync.NewCond(&o.lock)
+	go o.run()
+	return o
+}
+
+func (o *outbox) push(script string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	// back-pressure: wait for the UI thread to catch up (unless this is all there is)
+	for !o.closed && o.size > 0 && o.size+len(script) > outboxMaxBytes {
+		o.space.Wait()
+	}
+	if !o.closed {
+		o.append(outboxMessage{script: script, queued: time.Now(), progressOf: -1})
+	}
+}
+
+// like push, but never waits; for callbacks that run on the UI thread, which would
+// be waiting on itself
+func (o *outbox) post(script string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if !o.closed {
+		o.append(outboxMessage{script: script, queued: time.Now(), progressOf: -1})
+	}
+}
+
+// counts the binding callbacks running on the UI thread; while one is, everything is
+// posted instead of pushed. whatever other goroutines deliver in the meantime doesn't
+// wait either, which only loosens the limit for a moment
+type uiCallbacks struct {
+	running atomic.Int32
+}
+
+func (c *uiCallbacks) run(fn func() error) error {
+	c.running.Add(1)
+	defer c.running.Add(-1)
+	return fn()
+}
+
+func (c *uiCallbacks) deliver(o *outbox, script string) {
+	if c.running.Load() > 0 {
+		o.post(script)
+	} else {
+		o.push(script)
+	}
+}
+
+func (o *outbox) pushProgress(callId int, script string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.closed {
+		return
+	}
+	for i, message := range o.pending {
+		if message.progressOf == callId {
+			o.size += len(script) - len(message.script)
+			o.pending[i].script = script
+			return
+		}
+	}
+	if o.size+len(script) > outboxMaxBytes {
+		o.dropped++
+		return
+	}
+	o.append(outboxMessage{script: script, queued: time.Now(), progressOf: callId})
+}
+
+func (o *outbox) append(message outboxMessage) {
+	o.pending = append(o.pending, message)
+	o.size += len(message.script)
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (o *outbox) depth() int {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return len(o.pending)
+}
+
+func (o *outbox) close() {
+	o.lock.Lock()
+	if !o.closed {
+		close(o.closing)
+	}
+	o.closed = true
+	o.pending = nil
+	o.size = 0
+	o.space.Broadcast()
+	o.lock.Unlock()
+}
+
+func (o *outbox) run() {
+	last := time.Now()
+	for range o.wake {
+		// everything that arrives until the next frame goes out together
+		if wait := outboxFrame - time.Since(last); wait > 0 {
+			time.Sleep(wait)
+		}
+		last = time.Now()
+
+		o.lock.Lock()
+		if o.closed {
+			o.lock.Unlock()
+			return
+		}
+		pending := o.pending
+		dropped := o.dropped
+		// the batch keeps its space until it was delivered
+		size := o.size
+		o.pending = nil
+		o.dropped = 0
+		o.lock.Unlock()
+
+		if len(pending) == 0 {
+			continue
+		}
+		if late := last.Sub(pending[0].queued); late > outboxLate {
+			logger.Warn("UI delivery is late", "late", late, "pending", len(pending))
+		}
+		if dropped > 0 {
+			logger.Debug("UI delivery dropped progress events", "dropped", dropped)
+		}
+
+		scripts := make([]string, len(pending))
+		for i, message := range pending {
+			// one failing snippet must not take the others down with it
+			scripts[i] = "try{" + message.script + "}catch(e){console.error(e)}"
+		}
+		delivered := make(chan struct{}, 1)
+		o.eval(strings.Join(scripts, ";"), func() {
+			select {
+			case delivered <- struct{}{}:
+			default:
+			}
+		})
+		select {
+		case <-delivered:
+		case <-time.After(outboxDeliveryTimeout):
+			logger.Warn("UI delivery timed out, sending the next batch anyway", "timeout", outboxDeliveryTimeout)
+		case <-o.closing:
+			return
+		}
+
+		o.lock.Lock()
+		if o.closed {
+			o.lock.Unlock()
+			return
+		}
+		o.size -= size
+		o.space.Broadcast()
+		o.lock.Unlock()
+	}
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// hands every batch to the test, which decides when the page is done with it
+type fakePage struct {
+	batches chan string
+	done    chan func()
+}
+
+func newFakePage() *fakePage {
+	return &fakePage{batches: make(chan string, 16), done: make(chan func(), 16)}
+}
+
+func (p *fakePage) eval(script string, done func()) {
+	p.batches <- script
+	p.done <- done
+}
+
+func waitFor[T any](t *testing.T, c chan T) T {
+	t.Helper()
+	select {
+	case value := <-c:
+		return value
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out")
+	}
+	panic("unreachable")
+}
+
+func pushInBackground(o *outbox, script string) chan struct{} {
+	pushed := make(chan struct{})
+	go func() {
+		o.push(script)
+		close(pushed)
+	}()
+	return pushed
+}
+
+func TestOutboxWaitsForDelivery(t *testing.T) {
+	page := newFakePage()
+	o := newOutbox(page.eval)
+	defer o.close()
+
+	big := strings.Repeat("x", outboxMaxBytes-4)
+	o.push(big)
+	if batch := waitFor(t, page.batches); !strings.Contains(batch, big) {
+		t.Fatal("the first batch is missing the message")
+	}
+	done := waitFor(t, page.done)
+
+	// the batch left the outbox, but the page hasn't run it yet: there is still no room
+	pushed := pushInBackground(o, "second()")
+	select {
+	case <-pushed:
+		t.Fatal("push didn't wait for the page")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	done()
+	waitFor(t, pushed)
+	if batch := waitFor(t, page.batches); !strings.Contains(batch, "second()") {
+		t.Fatalf("second batch = %q", batch)
+	}
+}
+
+// the UI thread is what runs the batches, it can't wait for them
+func TestOutboxPostNeverWaits(t *testing.T) {
+	page := newFakePage()
+	o := newOutbox(page.eval)
+	defer o.close()
+
+	o.push(strings.Repeat("x", outboxMaxBytes-4))
+	waitFor(t, page.batches)
+
+	posted := make(chan struct{})
+	go func() {
+		callbacks := &uiCallbacks{}
+		callbacks.run(func() error {
+			callbacks.deliver(o, "fromTheUIThread()")
+			return nil
+		})
+		close(posted)
+	}()
+	waitFor(t, posted)
+	if o.depth() != 1 {
+		t.Fatalf("depth = %d", o.depth())
+	}
+}
+
+func TestOutboxDeliveryTimeout(t *testing.T) {
+	previous := outboxDeliveryTimeout
+	outboxDeliveryTimeout = 50 * time.Millisecond
+	defer func() { outboxDeliveryTimeout = previous }()
+
+	page := newFakePage()
+	o := newOutbox(page.eval)
+	defer o.close()
+
+	// a page that never reports back
+	o.push(strings.Repeat("x", outboxMaxBytes-4))
+	waitFor(t, page.batches)
+	waitFor(t, pushInBackground(o, "afterTheHang()"))
+	if batch := waitFor(t, page.batches); !strings.Contains(batch, "afterTheHang()") {
+		t.Fatalf("batch = %q", batch)
+	}
+}
+
+func TestOutboxCloseReleasesWaiters(t *testing.T) {
+	page := newFakePage()
+	o := newOutbox(page.eval)
+
+	o.push(strings.Repeat("x", outboxMaxBytes-4))
+	waitFor(t, page.batches)
+	pushed := pushInBackground(o, "never()")
+	time.Sleep(20 * time.Millisecond)
+	o.close()
+	waitFor(t, pushed)
+}
+
+// progress doesn't wait, it is dropped once the outbox is full and replaced while queued
+func TestOutboxProgress(t *testing.T) {
+	o := newOutbox(func(script string, done func()) {})
+	defer o.close()
+
+	o.lock.Lock()
+	o.size = outboxMaxBytes
+	o.lock.Unlock()
+	o.pushProgress(1, "progress(1, 10)")
+	if o.depth() != 0 {
+		t.Fatal("progress was queued in a full outbox")
+	}
+
+	o.lock.Lock()
+	o.size = 0
+	o.lock.Unlock()
+	o.pushProgress(2, "progress(2, 10)")
+	o.pushProgress(2, "progress(2, 20)")
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if len(o.pending) > 1 || (len(o.pending) == 1 && o.pending[0].script != "progress(2, 20)") {
+		t.Fatalf("pending = %+v", o.pending)
+	}
+}
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// the page based UIs deliver everything by evaluating small snippets
+// that call into the saged / event functions the frontend provides
+
+func resolveScript(callId int, result json.RawMessage) string {
+	return fmt.Sprintf("if(saged[%d]){saged[%d].a(%s);delete saged[%d]}", callId, callId, result, callId)
+}
+
+func rejectScript(callId int, err json.RawMessage) string {
+	return fmt.Sprintf("if(saged[%d]){saged[%d].b(Object.assign(new Error(),%s));delete saged[%d]}", callId, callId, err, callId)
+}
+
+func progressScript(callId int, event json.RawMessage) string {
+	// older frontends don't know about progress events
+	return fmt.Sprintf("if(saged[%d]&&saged[%d].p){saged[%d].p(%s)}", callId, callId, callId, event)
+}
+
+func emitScript(event string, args []any) (string, error) {
+	encoded := make([]string, len(args))
+	for i, arg := range args {
+		data, err := json.Marshal(arg)
+		if err != nil {
+			return "", err
+		}
+		encoded[i] = string(data)
+	}
+	return fmt.Sprintf("%s(%s)", event, strings.Join(encoded, ",")), nil
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/sag-enhanced/native-app/src/options"
@@ -9,9 +10,10 @@ import (
 func getScripts(options *options.Options) []string {
 	scripts := []string{}
 
-	// arbitrary redirect protection
+	// arbitrary redirect protection, the UIs also stop these themselves (see navigation.go)
+	trusted, _ := json.Marshal(options.GetTrustedOrigins())
 	origin := options.GetRealmOrigin()
-	js := fmt.Sprintf("if([%q,'https://id.sage.party'].indexOf(location.origin)===-1)location.href=%q", origin, origin)
+	js := fmt.Sprintf("if(%s.indexOf(location.origin)===-1)location.href=%q", trusted, origin)
 	scripts = append(scripts, js)
 
 	// expose current URL
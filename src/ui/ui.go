@@ -1,21 +1,56 @@
 package ui
 
-import "github.com/sag-enhanced/native-app/src/options"
+import (
+	"encoding/json"
+
+	"github.com/sag-enhanced/native-app/src/logging"
+	"github.com/sag-enhanced/native-app/src/options"
+)
 
 type UII interface {
 	Run()
 	Eval(code string)
 	SetBindHandler(handler bindHandler)
+	// navigations out of the realm that were stopped; UIs without navigation never call it
+	SetNavigationHandler(handler navigationHandler)
+	// the page stopped answering the watchdog; UIs without a renderer never call it
+	SetHangHandler(handler hangHandler)
 	Navigate(url string)
 	Quit()
+	// brings the window to the front, eg. when the app is launched a second time
+	Focus()
+
+	// delivery of RPC results and events to the frontend
+	Resolve(callId int, result json.RawMessage)
+	Reject(callId int, err json.RawMessage)
+	Progress(callId int, event json.RawMessage)
+	Emit(event string, args ...any)
+}
+
+// UIs whose caller outlives a navigation (the headless client) still have to answer the
+// calls whose results were dropped because of it
+type DroppedCalls interface {
+	Dropped(callId int, err json.RawMessage)
 }
 
-type bindHandler func(method string, callId int, params string) error
+// UIs with a native main loop
+type NativeLoop interface {
+	// runs fn on the UI thread, once the main loop is up
+	OnUIThread(fn func())
+}
+
+var logger = logging.For("ui")
+
+// origin is empty if the UI can't tell who is calling
+type bindHandler func(origin string, method string, callId int, params string) error
 
 func NewUI(opt *options.Options) UII {
-	if opt.UI == options.PlaywrightUI {
+	switch opt.UI {
+	case options.PlaywrightUI:
 		return createPlaywrightUII(opt)
-	} else {
+	case options.StdioUI, options.SocketUI:
+		return createHeadlessUII(opt)
+	default:
 		return createWebviewUII(opt)
 	}
 }
@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/sag-enhanced/native-app/src/options"
+)
+
+// the headless UI has no page at all; instead it speaks line-delimited JSON-RPC 2.0
+// over stdin/stdout or a unix socket, so the backend can be scripted from CI
+// and end-to-end tests without GTK or Chromium
+
+// calls with negative ids are issued by the UI itself and never answered
+const internalCallId = -1
+
+const (
+	rpcInvalidRequest = -32600
+	rpcCallFailed     = -32000
+	rpcCallRejected   = -32001
+)
+
+type HeadlessUII struct {
+	options     *options.Options
+	bindHandler bindHandler
+
+	writer    io.Writer
+	writeLock sync.Mutex
+	listener  net.Listener
+	pending   sync.WaitGroup
+	quit      chan struct{}
+	quitOnce  sync.Once
+}
+
+func createHeadlessUII(opt *options.Options) *HeadlessUII {
+	hui := &HeadlessUII{
+		options: opt,
+		quit:    make(chan struct{}),
+	}
+	if opt.UI == options.StdioUI {
+		// stdout belongs to the protocol now; everything else that prints
+		// (verbose logging, child processes, ...) gets moved to stderr
+		hui.writer = os.Stdout
+		os.Stdout = os.Stderr
+	}
+	return hui
+}
+
+func (hui *HeadlessUII) Run() {
+	// there is no page that could report its URL, so we pretend to be on the realm
+	hui.Navigate(hui.options.GetRealmOrigin())
+
+	if hui.options.UI == options.StdioUI {
+		done := make(chan struct{})
+		go func() {
+			hui.serve(os.Stdin)
+			// input is closed, but calls may still be running
+			hui.pending.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-hui.quit:
+		}
+		return
+	}
+
+	os.Remove(hui.options.UISocket)
+	listener, err := net.Listen("unix", hui.options.UISocket)
+	if err != nil {
+		logger.Error("Error while listening on socket", "error", err)
+		return
+	}
+	defer os.Remove(hui.options.UISocket)
+	hui.listener = listener
+	logger.Info("Listening for JSON-RPC connections", "socket", hui.options.UISocket)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logger.Error("Error while accepting connection", "error", err)
+			}
+			return
+		}
+		logger.Debug("JSON-RPC client connected")
+		// one client at a time; call ids are only unique per client
+		hui.setWriter(conn)
+		hui.serve(conn)
+		hui.setWriter(nil)
+		conn.Close()
+	}
+}
+
+func (hui *HeadlessUII) serve(reader io.Reader) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var request rpcRequest
+		if err := json.Unmarshal(line, &request); err != nil {
+			hui.send(rpcMessage{Error: &rpcError{Code: rpcInvalidRequest, Message: err.Error()}})
+			continue
+		}
+		if request.Id == nil || *request.Id < 0 || request.Method == "" {
+			hui.send(rpcMessage{Id: request.Id, Error: &rpcError{Code: rpcInvalidRequest, Message: "id must be a non-negative integer and method must be set"}})
+			continue
+		}
+		params := string(request.Params)
+		if params == "" || params == "null" {
+			params = "[]"
+		}
+		hui.pending.Add(1)
+		if err := hui.bindHandler("", request.Method, *request.Id, params); err != nil {
+			hui.pending.Done()
+			hui.send(rpcMessage{Id: request.Id, Error: &rpcError{Code: rpcCallFailed, Message: err.Error()}})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Error("Error while reading JSON-RPC input", "error", err)
+	}
+}
+
+func (hui *HeadlessUII) setWriter(writer io.Writer) {
+	hui.writeLock.Lock()
+	hui.writer = writer
+	hui.writeLock.Unlock()
+}
+
+func (hui *HeadlessUII) send(message rpcMessage) {
+	message.Version = "2.0"
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		logger.Error("Failed to encode JSON-RPC message", "error", err)
+		return
+	}
+	hui.writeLock.Lock()
+	defer hui.writeLock.Unlock()
+	if hui.writer == nil {
+		// results may carry secrets, the log only gets to know which message it was
+		id := -1
+		if message.Id != nil {
+			id = *message.Id
+		}
+		logger.Debug("No JSON-RPC client connected, dropping message", "id", id, "method", message.Method, "bytes", len(encoded))
+		return
+	}
+	hui.writer.Write(append(encoded, '\n'))
+}
+
+func (hui *HeadlessUII) Navigate(url string) {
+	logger.Debug("Navigate", "url", url)
+	params, _ := json.Marshal([]string{url, hui.options.CurrentUrlSecret})
+	if err := hui.bindHandler("", "setUrl", internalCallId, string(params)); err != nil {
+		logger.Error("Failed to set URL", "error", err)
+	}
+	hui.Emit("navigate", url)
+}
+
+func (hui *HeadlessUII) Eval(code string) {
+	// nothing to evaluate this in; clients may still want to know about it
+	hui.Emit("eval", code)
+}
+
+func (hui *HeadlessUII) Quit() {
+	hui.quitOnce.Do(func() {
+		close(hui.quit)
+		if hui.listener != nil {
+			hui.listener.Close()
+		}
+	})
+}
+
+func (hui *HeadlessUII) Focus() {
+	// there is no window
+}
+
+func (hui *HeadlessUII) SetBindHandler(handler bindHandler) {
+	hui.bindHandler = handler
+}
+
+// the client is the page, there is nothing that could navigate
+func (hui *HeadlessUII) SetNavigationHandler(handler navigationHandler) {}
+
+// a client that stops reading is noticed by the outbox instead
+func (hui *HeadlessUII) SetHangHandler(handler hangHandler) {}
+
+func (hui *HeadlessUII) Resolve(callId int, result json.RawMessage) {
+	if callId < 0 {
+		return
+	}
+	defer hui.pending.Done()
+	hui.send(rpcMessage{Id: &callId, Result: result})
+}
+
+func (hui *HeadlessUII) Reject(callId int, err json.RawMessage) {
+	if callId < 0 {
+		logger.Error("Internal call failed", "error", string(err))
+		return
+	}
+	defer hui.pending.Done()
+	var rejection struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(err, &rejection)
+	hui.send(rpcMessage{Id: &callId, Error: &rpcError{Code: rpcCallRejected, Message: rejection.Message, Data: err}})
+}
+
+// the client is still waiting for them (and for the stdio UI, so is the shutdown)
+func (hui *HeadlessUII) Dropped(callId int, err json.RawMessage) {
+	hui.Reject(callId, err)
+}
+
+func (hui *HeadlessUII) Progress(callId int, event json.RawMessage) {
+	if callId < 0 {
+		return
+	}
+	hui.Emit("progress", callId, event)
+}
+
+func (hui *HeadlessUII) Emit(event string, args ...any) {
+	params, err := json.Marshal(args)
+	if err != nil {
+		logger.Error("Failed to encode event", "event", event, "error", err)
+		return
+	}
+	hui.send(rpcMessage{Method: event, Params: params})
+}
+
+type rpcRequest struct {
+	Id     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type rpcMessage struct {
+	Version string          `json:"jsonrpc"`
+	Id      *int            `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
@@ -1,11 +1,15 @@
 package ui
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
+	"path"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
+	"github.com/sag-enhanced/native-app/src/logging"
 	"github.com/sag-enhanced/native-app/src/options"
 )
 
@@ -14,15 +18,50 @@ type PlaywrightUII struct {
 	mainThread  chan func()
 	options     *options.Options
 	bindHandler bindHandler
+	outbox      *outbox
+	// playwright waits for the bindings before it runs anything else on the page
+	callbacks uiCallbacks
+	// reports navigations that were stopped
+	navigationHandler navigationHandler
+	watchdog          *watchdog
 }
 
 func createPlaywrightUII(options *options.Options) *PlaywrightUII {
-	return &PlaywrightUII{options: options}
+	pwui := &PlaywrightUII{options: options}
+	pwui.outbox = newOutbox(pwui.evalThen)
+	pwui.watchdog = newWatchdog(MainWindow, pwui.outbox, !options.PlaywrightHeadless, func() {
+		// a busy main thread doesn't answer either
+		select {
+		case pwui.mainThread <- func() { pwui.page.Evaluate(pingScript) }:
+		default:
+		}
+	}, func() {
+		// not through the main thread, that may be stuck on the hung page
+		pwui.page.Goto(options.GetRealmOrigin())
+	})
+	return pwui
+}
+
+// MkdirAll leaves the mode of directories that already exist alone
+func privateDirectory(directory string) error {
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return err
+	}
+	return os.Chmod(directory, 0700)
 }
 
+// the profile (and with it localStorage of the realm) is kept between launches; traces
+// and HARs are only written with -playwrighttrace. neither is encrypted, so both are only
+// readable by the user
 func (pwui *PlaywrightUII) Run() {
-	options := playwright.BrowserTypeLaunchOptions{
-		Headless: playwright.Bool(false),
+	directory := path.Join(pwui.options.DataDirectory, "playwright")
+	for _, private := range []string{directory, path.Join(directory, "profile")} {
+		if err := privateDirectory(private); err != nil {
+			logger.Warn("Failed to restrict the playwright directory", "path", private, "error", err)
+		}
+	}
+	options := playwright.BrowserTypeLaunchPersistentContextOptions{
+		Headless: playwright.Bool(pwui.options.PlaywrightHeadless),
 		Args: []string{
 			"--disable-blink-features=AutomationControlled",
 		},
@@ -30,38 +69,77 @@ func (pwui *PlaywrightUII) Run() {
 			// disables "Chrome is being controlled by automated test software" banner
 			"--enable-automation",
 		},
+		// a headless browser has no window that could give the page its size
+		NoViewport: playwright.Bool(!pwui.options.PlaywrightHeadless),
+	}
+	// everything the page sends and receives ends up in these, secrets included
+	stamp := time.Now().Format("20060102-150405")
+	tracePath := path.Join(directory, "traces", "trace-"+stamp+".zip")
+	harPath := path.Join(directory, "traces", "har-"+stamp+".har")
+	if pwui.options.PlaywrightTrace {
+		options.RecordHarPath = playwright.String(harPath)
+		if err := privateDirectory(path.Dir(tracePath)); err != nil {
+			logger.Warn("Failed to create trace directory", "error", err)
+		}
+		logger.Warn("Recording a playwright trace, it contains everything the app sees (including secrets)", "path", tracePath)
 	}
 
-	playwright.Install(&playwright.RunOptions{
-		Browsers: []string{"chromium"},
-	})
-
-	pw, err := playwright.Run()
-
+	pw, err := startPlaywright()
 	if err != nil {
-		fmt.Println("Error while starting playwright: ", err)
+		logger.Error("Error while starting playwright", "error", err)
 		return
 	}
 	defer pw.Stop()
 
-	browser, err := pw.Chromium.Launch(options)
+	browser, err := pw.Chromium.LaunchPersistentContext(path.Join(directory, "profile"), options)
 	if err != nil {
-		fmt.Println("Error while launching browser: ", err)
+		logger.Error("Error while launching browser", "error", err)
 		return
 	}
+	if pwui.options.PlaywrightTrace {
+		// the HAR is written when the browser is closed
+		defer func() {
+			for _, filename := range []string{tracePath, harPath} {
+				if err := os.Chmod(filename, 0600); err != nil && !os.IsNotExist(err) {
+					logger.Warn("Failed to restrict the trace", "path", filename, "error", err)
+				}
+			}
+		}()
+	}
 	defer browser.Close()
 
-	pwui.page, err = browser.NewPage(playwright.BrowserNewPageOptions{
-		NoViewport: playwright.Bool(true),
-	})
-	if err != nil {
-		fmt.Println("Error while creating new page: ", err)
+	if pwui.options.PlaywrightTrace {
+		err := browser.Tracing().Start(playwright.TracingStartOptions{
+			Screenshots: playwright.Bool(true),
+			Snapshots:   playwright.Bool(true),
+			Sources:     playwright.Bool(true),
+		})
+		if err != nil {
+			logger.Warn("Failed to start tracing", "error", err)
+		} else {
+			defer func() {
+				if err := browser.Tracing().Stop(tracePath); err != nil {
+					logger.Warn("Failed to save trace", "error", err)
+				}
+			}()
+		}
+	}
+
+	// the persistent context comes with a page already
+	if pages := browser.Pages(); len(pages) > 0 {
+		pwui.page = pages[0]
+	} else if pwui.page, err = browser.NewPage(); err != nil {
+		logger.Error("Error while creating new page", "error", err)
 		return
 	}
 	defer pwui.page.Close()
 
 	pwui.mainThread = make(chan func())
 	pwui.initBinding()
+	if err := pwui.guardNavigation(browser); err != nil {
+		logger.Error("Error while setting up the navigation guard", "error", err)
+		return
+	}
 
 	scripts := getScripts(pwui.options)
 	for _, script := range scripts {
@@ -70,12 +148,17 @@ func (pwui *PlaywrightUII) Run() {
 		})
 	}
 
+	pwui.page.OnCrash(func(_ playwright.Page) {
+		pwui.watchdog.crashed()
+	})
 	pwui.page.OnClose(func(_ playwright.Page) {
 		// this will wake-up the main thread which will then realize its time to exit
 		pwui.mainThread <- func() {}
 	})
 
-	pwui.page.Goto(pwui.options.GetRealmOrigin())
+	pwui.page.Goto(pwui.options.GetStartUrl())
+	pwui.watchdog.start()
+	defer pwui.watchdog.close()
 
 	for !pwui.page.IsClosed() {
 		select {
@@ -83,36 +166,38 @@ func (pwui *PlaywrightUII) Run() {
 			fn()
 		}
 	}
+	// nobody is running the main thread anymore
+	pwui.outbox.close()
 }
 
 func (pwui *PlaywrightUII) Navigate(url string) {
-	if pwui.options.Verbose {
-		fmt.Println("Navigate:", url)
-	}
+	logger.Debug("Navigate", "url", url)
 	pwui.mainThread <- func() {
 		pwui.page.Goto(url)
 	}
 }
 
 func (pwui *PlaywrightUII) Eval(code string) {
-	if pwui.options.Verbose {
-		fmt.Println("Eval:", code)
-	}
+	pwui.evalThen(code, func() {})
+}
+
+// done runs once the code was evaluated
+func (pwui *PlaywrightUII) evalThen(code string, done func()) {
+	logger.Debug("Eval", "bytes", len(code))
 	result := make(chan bool, 1)
 	pwui.mainThread <- func() {
 		pwui.page.Evaluate(code)
 		result <- true
+		done()
 	}
 
-	if pwui.options.Verbose {
-		go func() {
-			select {
-			case <-result:
-			case <-time.After(5 * time.Second):
-				fmt.Println("Eval timed out. Something is returning a promise that doesn't resolve. Please contact support.")
-			}
-		}()
-	}
+	go func() {
+		select {
+		case <-result:
+		case <-time.After(5 * time.Second):
+			logger.Warn("Eval timed out. Something is returning a promise that doesn't resolve. Please contact support.", "outbox", pwui.outbox.depth())
+		}
+	}()
 }
 
 func (pwui *PlaywrightUII) Quit() {
@@ -121,6 +206,12 @@ func (pwui *PlaywrightUII) Quit() {
 	}
 }
 
+func (pwui *PlaywrightUII) Focus() {
+	pwui.mainThread <- func() {
+		pwui.page.BringToFront()
+	}
+}
+
 func (pwui *PlaywrightUII) initBinding() {
 	pwui.page.ExposeBinding("sage", func(source *playwright.BindingSource, args ...any) any {
 		if len(args) != 3 {
@@ -132,18 +223,103 @@ func (pwui *PlaywrightUII) initBinding() {
 		}
 
 		callerOrigin := fmt.Sprintf("%s://%s", caller.Scheme, caller.Host)
-		if callerOrigin != pwui.options.GetRealmOrigin() && callerOrigin != "https://id.sage.party" {
-			return fmt.Errorf("sage() is not allowed to be called from %q", callerOrigin)
-		}
 
 		method := args[0].(string)
 		callId := args[1].(int)
 		params := args[2].(string)
 
-		return pwui.bindHandler(method, callId, params)
+		return pwui.callbacks.run(func() error {
+			return pwui.bindHandler(callerOrigin, method, callId, params)
+		})
+	})
+	pwui.page.ExposeBinding("sagep", func(source *playwright.BindingSource, args ...any) any {
+		pwui.watchdog.pong()
+		return nil
 	})
 }
 
 func (pwui *PlaywrightUII) SetBindHandler(handler bindHandler) {
 	pwui.bindHandler = handler
 }
+
+func (pwui *PlaywrightUII) SetNavigationHandler(handler navigationHandler) {
+	pwui.navigationHandler = handler
+}
+
+func (pwui *PlaywrightUII) SetHangHandler(handler hangHandler) {
+	pwui.watchdog.setHandler(handler)
+}
+
+// top level navigations out of the realm are aborted before they are sent, in popups too.
+// redirects don't go through routes, so whatever still gets through is sent back once
+// it has loaded
+func (pwui *PlaywrightUII) guardNavigation(browser playwright.BrowserContext) error {
+	err := browser.Route("**/*", func(route playwright.Route) {
+		request := route.Request()
+		if request.IsNavigationRequest() && request.Frame() != nil && request.Frame().ParentFrame() == nil && !allowedNavigation(pwui.options, request.URL()) {
+			window := MainWindow
+			if request.Frame().Page() != pwui.page {
+				window = "popup"
+			}
+			blockNavigation(pwui.options, pwui.navigationHandler, window, request.URL())
+			route.Abort("blockedbyclient")
+			return
+		}
+		route.Continue()
+	})
+	if err != nil {
+		return err
+	}
+	pwui.page.OnFrameNavigated(func(frame playwright.Frame) {
+		if frame.ParentFrame() == nil && !allowedNavigation(pwui.options, frame.URL()) {
+			blockNavigation(pwui.options, pwui.navigationHandler, MainWindow, frame.URL())
+			go pwui.Navigate(pwui.options.GetRealmOrigin())
+		}
+	})
+	return nil
+}
+
+func (pwui *PlaywrightUII) Resolve(callId int, result json.RawMessage) {
+	pwui.callbacks.deliver(pwui.outbox, resolveScript(callId, result))
+}
+
+func (pwui *PlaywrightUII) Reject(callId int, err json.RawMessage) {
+	pwui.callbacks.deliver(pwui.outbox, rejectScript(callId, err))
+}
+
+func (pwui *PlaywrightUII) Progress(callId int, event json.RawMessage) {
+	pwui.outbox.pushProgress(callId, progressScript(callId, event))
+}
+
+func (pwui *PlaywrightUII) Emit(event string, args ...any) {
+	script, err := emitScript(event, args)
+	if err != nil {
+		logger.Error("Failed to encode event", "event", event, "error", err)
+		return
+	}
+	pwui.callbacks.deliver(pwui.outbox, script)
+}
+
+// the driver and chromium are only installed when they are missing (or outdated), which
+// saves a download check on every start
+func startPlaywright() (*playwright.Playwright, error) {
+	runOptions := &playwright.RunOptions{
+		Browsers: []string{"chromium"},
+		Verbose:  true,
+		Stdout:   logging.Stream(logger, "stdout"),
+		Stderr:   logging.Stream(logger, "stderr"),
+		Logger:   logger,
+	}
+	pw, err := playwright.Run(runOptions)
+	if err == nil {
+		if _, err = os.Stat(pw.Chromium.ExecutablePath()); err == nil {
+			return pw, nil
+		}
+		pw.Stop()
+	}
+	logger.Info("Installing playwright", "reason", err)
+	if err := playwright.Install(runOptions); err != nil {
+		return nil, err
+	}
+	return playwright.Run(runOptions)
+}
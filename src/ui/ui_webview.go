@@ -1,70 +1,579 @@
 package ui
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+	"unsafe"
 
 	"github.com/sag-enhanced/native-app/src/options"
 	webview_go "github.com/sag-enhanced/webview_go"
 )
 
+// how often the windows are checked for being moved or closed
+const windowPollInterval = 500 * time.Millisecond
+
 type WebviewUII struct {
+	// the main window; everything is dispatched to the UI thread through it
 	webview     webview_go.WebView
 	options     *options.Options
 	bindHandler bindHandler
+	outbox      *outbox
+	// the bindings run on the UI thread
+	callbacks uiCallbacks
+	// reports navigations that were stopped
+	navigationHandler navigationHandler
+	// watches the page of the main window
+	watchdog *watchdog
+
+	// run once the main loop is up, see OnUIThread
+	startup     []func()
+	startupLock sync.Mutex
+
+	// everything below is only touched on the UI thread, except where noted
+	windows map[string]*webviewWindow
+	// set once the main loop is over, nothing may touch the webviews anymore; written
+	// under dispatchLock, so dispatch can check it from any goroutine
+	stopped      bool
+	dispatchLock sync.RWMutex
+	done         chan struct{}
+
+	// guards the call ids of the other windows, which are used from any goroutine
+	callLock   sync.Mutex
+	calls      map[int]windowCall
+	nextCallId int
+}
+
+type webviewWindow struct {
+	name    string
+	webview webview_go.WebView
+	outbox  *outbox
+	// the browser controller, kept to unregister it once it's gone
+	browser unsafe.Pointer
+	// navigations are stopped natively; if not, the URL the page reports is checked
+	guarded bool
+	// reported by the page of the window; the main window reports to the bindings instead
+	origin     string
+	originLock sync.Mutex
+}
+
+type windowCall struct {
+	window *webviewWindow
+	callId int
 }
 
 func createWebviewUII(options *options.Options) *WebviewUII {
-	return &WebviewUII{
-		options: options,
+	wui := &WebviewUII{
+		options:    options,
+		windows:    map[string]*webviewWindow{},
+		done:       make(chan struct{}),
+		calls:      map[int]windowCall{},
+		nextCallId: WindowCallIds,
 	}
+	wui.outbox = newOutbox(wui.evalThen)
+	wui.watchdog = newWatchdog(MainWindow, wui.outbox, true, func() {
+		wui.dispatch(func() {
+			if !wui.stopped {
+				wui.webview.Eval(pingScript)
+			}
+		})
+	}, func() {
+		wui.Navigate(options.GetRealmOrigin())
+	})
+	return wui
 }
 
 func (wui *WebviewUII) Run() {
+	loadGeometries(wui.options.DataDirectory)
+
+	wui.startupLock.Lock()
+	wui.dispatchLock.Lock()
 	wui.webview = webview_go.New(true)
+	wui.dispatchLock.Unlock()
+	for _, fn := range wui.startup {
+		wui.webview.Dispatch(fn)
+	}
+	wui.startup = nil
+	wui.startupLock.Unlock()
 	defer wui.webview.Destroy()
 
-	wui.webview.SetTitle(fmt.Sprintf("SAG Enhanced (b%d)", wui.options.Build))
-	wui.webview.SetSize(800, 600, webview_go.HintNone)
+	main := &webviewWindow{name: MainWindow, webview: wui.webview, outbox: wui.outbox}
+	wui.windows[MainWindow] = main
+	wui.setupWindow(main, WindowOptions{})
+	if wui.options.CloseToTray {
+		if err := hideOnClose(wui.webview.Window()); err != nil {
+			logger.Warn("Closing the window will quit the app", "error", err)
+		}
+	}
+
+	wui.webview.Navigate(wui.options.GetStartUrl())
+
+	stopPolling := make(chan struct{})
+	go wui.pollWindows(stopPolling)
+	wui.watchdog.start()
+	wui.webview.Run()
+	wui.watchdog.close()
+	close(stopPolling)
+	wui.dispatchLock.Lock()
+	wui.stopped = true
+	wui.dispatchLock.Unlock()
+	close(wui.done)
+
+	for _, window := range wui.windows {
+		if window != main {
+			wui.destroyWindow(window)
+		}
+	}
+	saveGeometries(wui.options.DataDirectory)
+	// the webview is destroyed after this, evaluating anything would crash
+	wui.outbox.close()
+}
+
+// on the UI thread
+func (wui *WebviewUII) setupWindow(window *webviewWindow, windowOptions WindowOptions) {
+	title := windowOptions.Title
+	if title == "" {
+		title = fmt.Sprintf("SAG Enhanced (b%d)", wui.options.Build)
+	}
+	window.webview.SetTitle(title)
 
-	wui.webview.Bind("sage", wui.bindHandler)
+	geometry, restored := getGeometry(window.name)
+	width, height := geometry.Width, geometry.Height
+	if !restored {
+		width, height = defaultWidth, defaultHeight
+		if windowOptions.Width > 0 && windowOptions.Height > 0 {
+			width, height = windowOptions.Width, windowOptions.Height
+		}
+	}
+	window.webview.SetSize(width, height, webview_go.HintNone)
+	if restored {
+		applyGeometry(window.webview.Window(), geometry)
+	}
 
+	window.webview.Bind("sage", func(method string, callId int, params string) error {
+		return wui.callbacks.run(func() error {
+			return wui.call(window, method, callId, params)
+		})
+	})
+	if window.name == MainWindow {
+		window.webview.Bind("sagep", wui.watchdog.pong)
+	}
 	for _, script := range getScripts(wui.options) {
-		wui.webview.Init(script)
+		window.webview.Init(script)
 	}
 
-	wui.webview.Navigate(wui.options.GetRealmOrigin())
-	wui.webview.Run()
+	window.browser = browserController(window.webview)
+	err := guardNavigation(window.browser, func(url string) bool {
+		if allowedNavigation(wui.options, url) {
+			return true
+		}
+		blockNavigation(wui.options, wui.navigationHandler, window.name, url)
+		return false
+	})
+	window.guarded = err == nil
+	if err != nil {
+		logger.Debug("No native navigation guard", "window", window.name, "error", err)
+	}
 }
 
-func (wui *WebviewUII) Navigate(url string) {
-	if wui.options.Verbose {
-		fmt.Println("Navigate:", url)
+// without a native guard, a page outside of the realm is only noticed once it reports its
+// URL, and then sent back to the realm
+func (wui *WebviewUII) checkUrl(window *webviewWindow, params string) {
+	var args []string
+	if json.Unmarshal([]byte(params), &args) != nil || len(args) == 0 || allowedNavigation(wui.options, args[0]) {
+		return
+	}
+	blockNavigation(wui.options, wui.navigationHandler, window.name, args[0])
+	wui.dispatch(func() {
+		if !wui.stopped && wui.windows[window.name] == window {
+			window.webview.Navigate(wui.options.GetRealmOrigin())
+		}
+	})
+}
+
+// the main window is watched for being closed too, as the others would keep the app running
+func (wui *WebviewUII) pollWindows(stop chan struct{}) {
+	ticker := time.NewTicker(windowPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		wui.dispatch(func() {
+			if wui.stopped {
+				return
+			}
+			for _, window := range wui.windows {
+				wui.pollWindow(window)
+			}
+		})
 	}
-	wui.webview.Dispatch(func() {
+}
+
+// on the UI thread
+func (wui *WebviewUII) pollWindow(window *webviewWindow) {
+	handle := window.webview.Window()
+	if handle == nil {
+		// closed by the user
+		if window.name == MainWindow {
+			wui.webview.Terminate()
+		} else {
+			wui.destroyWindow(window)
+			saveGeometries(wui.options.DataDirectory)
+		}
+		return
+	}
+
+	geometry, minimized, ok := readGeometry(handle)
+	if !ok || minimized {
+		return
+	}
+	if geometry.Maximized {
+		// keep the size it goes back to
+		if previous, ok := getGeometry(window.name); ok {
+			geometry.X, geometry.Y, geometry.Width, geometry.Height = previous.X, previous.Y, previous.Width, previous.Height
+		}
+	}
+	if geometry.valid() {
+		setGeometry(window.name, geometry)
+	}
+}
+
+// on the UI thread
+func (wui *WebviewUII) destroyWindow(window *webviewWindow) {
+	delete(wui.windows, window.name)
+	unguardNavigation(window.browser)
+	window.outbox.close()
+	window.webview.Destroy()
+
+	wui.callLock.Lock()
+	for id, c := range wui.calls {
+		if c.window == window {
+			delete(wui.calls, id)
+		}
+	}
+	wui.callLock.Unlock()
+	logger.Debug("Window closed", "window", window.name)
+}
+
+func (wui *WebviewUII) call(window *webviewWindow, method string, callId int, params string) error {
+	if method == "setUrl" && !window.guarded {
+		wui.checkUrl(window, params)
+	}
+	if window.name == MainWindow {
+		return wui.bindHandler("", method, callId, params)
+	}
+
+	switch method {
+	case "setUrl":
+		// the same check the bindings do for the main window
+		var args []string
+		if json.Unmarshal([]byte(params), &args) != nil || len(args) != 2 || args[1] != wui.options.CurrentUrlSecret {
+			wui.Quit()
+			return nil
+		}
+		origin := "null"
+		if u, err := url.Parse(args[0]); err == nil {
+			origin = u.Scheme + "://" + u.Host
+		}
+		window.originLock.Lock()
+		window.origin = origin
+		window.originLock.Unlock()
+		window.outbox.post(resolveScript(callId, json.RawMessage("null")))
+		return nil
+	case "cancel":
+		var args []int
+		if json.Unmarshal([]byte(params), &args) == nil && len(args) == 1 {
+			params = fmt.Sprintf("[%d]", wui.findCall(window, args[0]))
+		}
+	}
+
+	window.originLock.Lock()
+	origin := window.origin
+	window.originLock.Unlock()
+	if origin == "" {
+		// the page hasn't said where it is yet, which must not fall back to the main window
+		origin = "null"
+	}
+
+	wui.callLock.Lock()
+	id := wui.nextCallId
+	wui.nextCallId++
+	wui.calls[id] = windowCall{window: window, callId: callId}
+	wui.callLock.Unlock()
+
+	err := wui.bindHandler(origin, method, id, params)
+	if err != nil {
+		wui.route(id, true)
+	}
+	return err
+}
+
+// the outbox of the window a call belongs to and the id its page knows it by; not ok
+// if the window is gone
+func (wui *WebviewUII) route(callId int, finished bool) (*outbox, int, bool) {
+	if callId < WindowCallIds {
+		return wui.outbox, callId, true
+	}
+	wui.callLock.Lock()
+	defer wui.callLock.Unlock()
+	c, ok := wui.calls[callId]
+	if !ok {
+		return nil, 0, false
+	}
+	if finished {
+		delete(wui.calls, callId)
+	}
+	return c.window.outbox, c.callId, true
+}
+
+func (wui *WebviewUII) findCall(window *webviewWindow, callId int) int {
+	wui.callLock.Lock()
+	defer wui.callLock.Unlock()
+	for id, c := range wui.calls {
+		if c.window == window && c.callId == callId {
+			return id
+		}
+	}
+	return -1
+}
+
+// dispatching to a webview that was destroyed (or not created yet) crashes, so everything
+// that may run outside of the main loop goes through here; false if fn will never run
+func (wui *WebviewUII) dispatch(fn func()) bool {
+	wui.dispatchLock.RLock()
+	defer wui.dispatchLock.RUnlock()
+	if wui.webview == nil || wui.stopped {
+		return false
+	}
+	wui.webview.Dispatch(fn)
+	return true
+}
+
+func (wui *WebviewUII) Navigate(url string) {
+	logger.Debug("Navigate", "url", url)
+	wui.dispatch(func() {
 		wui.webview.Navigate(url)
 	})
 }
 
 func (wui *WebviewUII) Eval(code string) {
-	if wui.options.Verbose {
-		fmt.Println("Eval:", code)
-	}
+	wui.evalThen(code, func() {})
+}
+
+// done runs once the code was evaluated, or once it's clear that it never will be
+func (wui *WebviewUII) evalThen(code string, done func()) {
+	logger.Debug("Eval", "bytes", len(code))
 	// there seems to be a rare bug in webview where sometimes the eval doesn't work
 	// so we try it a few times (the code is idempotent so it's safe to retry)
 	// 3 tries should be enough
 	// for i := 0; i < 3; i++ {
-	wui.webview.Dispatch(func() {
+	dispatched := wui.dispatch(func() {
 		wui.webview.Eval(code)
+		done()
 	})
 	// }
+	if !dispatched {
+		done()
+	}
 }
 
 func (wui *WebviewUII) Quit() {
-	wui.webview.Dispatch(func() {
+	wui.dispatch(func() {
 		wui.webview.Terminate()
 	})
 }
 
+func (wui *WebviewUII) Focus() {
+	wui.dispatch(func() {
+		focusWindow(wui.webview.Window())
+	})
+}
+
+// for native integrations that have to live on the UI thread (like the tray)
+func (wui *WebviewUII) OnUIThread(fn func()) {
+	wui.startupLock.Lock()
+	defer wui.startupLock.Unlock()
+	if wui.webview == nil {
+		wui.startup = append(wui.startup, fn)
+		return
+	}
+	wui.dispatch(fn)
+}
+
 func (wui *WebviewUII) SetBindHandler(handler bindHandler) {
 	wui.bindHandler = handler
 }
+
+func (wui *WebviewUII) SetNavigationHandler(handler navigationHandler) {
+	wui.navigationHandler = handler
+}
+
+func (wui *WebviewUII) SetHangHandler(handler hangHandler) {
+	wui.watchdog.setHandler(handler)
+}
+
+func (wui *WebviewUII) Resolve(callId int, result json.RawMessage) {
+	if outbox, id, ok := wui.route(callId, true); ok {
+		wui.callbacks.deliver(outbox, resolveScript(id, result))
+	}
+}
+
+func (wui *WebviewUII) Reject(callId int, err json.RawMessage) {
+	if outbox, id, ok := wui.route(callId, true); ok {
+		wui.callbacks.deliver(outbox, rejectScript(id, err))
+	}
+}
+
+func (wui *WebviewUII) Progress(callId int, event json.RawMessage) {
+	if outbox, id, ok := wui.route(callId, false); ok {
+		outbox.pushProgress(id, progressScript(id, event))
+	}
+}
+
+// events only go to the main window; the others run the same frontend, which would
+// handle them twice
+func (wui *WebviewUII) Emit(event string, args ...any) {
+	script, err := emitScript(event, args)
+	if err != nil {
+		logger.Error("Failed to encode event", "event", event, "error", err)
+		return
+	}
+	wui.callbacks.deliver(wui.outbox, script)
+}
+
+// runs fn on the UI thread with the named window and waits for it
+func (wui *WebviewUII) withWindow(name string, fn func(window *webviewWindow) error) error {
+	if name == "" {
+		name = MainWindow
+	}
+	result := make(chan error, 1)
+	dispatched := wui.dispatch(func() {
+		window, ok := wui.windows[name]
+		if wui.stopped || !ok || window.webview.Window() == nil {
+			result <- ErrNoWindow
+			return
+		}
+		result <- fn(window)
+	})
+	if !dispatched {
+		return ErrNoWindow
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-wui.done:
+		return ErrNoWindow
+	}
+}
+
+// opening a window that is already open brings it to the front and navigates it instead
+func (wui *WebviewUII) OpenWindow(name string, url string, windowOptions WindowOptions) error {
+	result := make(chan error, 1)
+	dispatched := wui.dispatch(func() {
+		if wui.stopped {
+			result <- ErrNoWindow
+			return
+		}
+		if window, ok := wui.windows[name]; ok {
+			if window.webview.Window() != nil {
+				focusWindow(window.webview.Window())
+				window.webview.Navigate(url)
+				result <- nil
+				return
+			}
+			// closed, but the poll hasn't noticed yet
+			wui.destroyWindow(window)
+		}
+
+		window := &webviewWindow{name: name, webview: webview_go.New(true)}
+		window.outbox = newOutbox(func(script string, done func()) {
+			dispatched := wui.dispatch(func() {
+				if window.webview.Window() != nil {
+					window.webview.Eval(script)
+				}
+				done()
+			})
+			if !dispatched {
+				done()
+			}
+		})
+		wui.windows[name] = window
+		wui.setupWindow(window, windowOptions)
+		window.webview.Navigate(url)
+		logger.Debug("Window opened", "window", name, "url", url)
+		result <- nil
+	})
+	if !dispatched {
+		return ErrNoWindow
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-wui.done:
+		return ErrNoWindow
+	}
+}
+
+func (wui *WebviewUII) CloseWindow(name string) error {
+	return wui.withWindow(name, func(window *webviewWindow) error {
+		if window.name == MainWindow {
+			wui.webview.Terminate()
+			return nil
+		}
+		// remember where it was, the poll may not have seen the latest move
+		wui.pollWindow(window)
+		wui.destroyWindow(window)
+		saveGeometries(wui.options.DataDirectory)
+		return nil
+	})
+}
+
+func (wui *WebviewUII) ListWindows() []string {
+	var names []string
+	wui.withWindow(MainWindow, func(*webviewWindow) error {
+		for name := range wui.windows {
+			names = append(names, name)
+		}
+		return nil
+	})
+	sort.Strings(names)
+	return names
+}
+
+func (wui *WebviewUII) SetWindowTitle(name string, title string) error {
+	return wui.withWindow(name, func(window *webviewWindow) error {
+		window.webview.SetTitle(title)
+		return nil
+	})
+}
+
+func (wui *WebviewUII) SetWindowSize(name string, width int, height int) error {
+	return wui.withWindow(name, func(window *webviewWindow) error {
+		window.webview.SetSize(width, height, webview_go.HintNone)
+		return nil
+	})
+}
+
+func (wui *WebviewUII) SetWindowAlwaysOnTop(name string, enabled bool) error {
+	return wui.withWindow(name, func(window *webviewWindow) error {
+		return setAlwaysOnTop(window.webview.Window(), enabled)
+	})
+}
+
+func (wui *WebviewUII) MinimizeWindow(name string) error {
+	return wui.withWindow(name, func(window *webviewWindow) error {
+		return minimizeWindow(window.webview.Window())
+	})
+}
+
+func (wui *WebviewUII) RestoreWindow(name string) error {
+	return wui.withWindow(name, func(window *webviewWindow) error {
+		return restoreWindow(window.webview.Window())
+	})
+}
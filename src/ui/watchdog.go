@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/sqweek/dialog"
+)
+
+// the page of the main window is pinged every few seconds and answers by calling sagep().
+// if it stops answering, its renderer (or the UI thread) hangs or crashed: what was still
+// pending is logged, and the user is asked whether to reload the realm. without anyone
+// to ask (headless, or no display to show the question on) it is reloaded right away
+
+const (
+	watchdogInterval = 5 * time.Second
+	// long enough for a slow page load
+	watchdogTimeout = 20 * time.Second
+	pingScript      = "window.sagep&&void window.sagep()"
+)
+
+// called when the page stops answering, for the diagnostics of whatever is waiting on it
+type hangHandler func(window string, since time.Duration)
+
+type watchdog struct {
+	window string
+	// sends pingScript to the page; must not block
+	ping   func()
+	reload func()
+	outbox *outbox
+	// somebody can be asked before reloading
+	attended bool
+
+	lock     sync.Mutex
+	answered time.Time
+	hung     bool
+	// the user is being asked, no need to ask again
+	asking  bool
+	handler hangHandler
+	stop    chan struct{}
+}
+
+func newWatchdog(window string, outbox *outbox, attended bool, ping func(), reload func()) *watchdog {
+	attended = attended && hasDisplay()
+	return &watchdog{window: window, outbox: outbox, attended: attended, ping: ping, reload: reload, stop: make(chan struct{})}
+}
+
+// dialogs need an X or Wayland server on the unixes; without one they panic
+func hasDisplay() bool {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return true
+	}
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+func (w *watchdog) setHandler(handler hangHandler) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.handler = handler
+}
+
+func (w *watchdog) start() {
+	w.lock.Lock()
+	w.answered = time.Now()
+	w.lock.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(watchdogInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+			w.check()
+			w.ping()
+		}
+	}()
+}
+
+func (w *watchdog) close() {
+	close(w.stop)
+}
+
+func (w *watchdog) pong() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.hung {
+		logger.Info("Page is responding again", "window", w.window, "after", time.Since(w.answered).Round(time.Second))
+	}
+	w.answered = time.Now()
+	w.hung = false
+}
+
+// a crashed renderer doesn't have to wait for the timeout
+func (w *watchdog) crashed() {
+	w.lock.Lock()
+	w.answered = time.Now().Add(-watchdogTimeout)
+	w.lock.Unlock()
+	logger.Error("Page crashed", "window", w.window)
+	w.check()
+}
+
+func (w *watchdog) check() {
+	w.lock.Lock()
+	since := time.Since(w.answered)
+	if w.hung || since < watchdogTimeout {
+		w.lock.Unlock()
+		return
+	}
+	w.hung = true
+	handler := w.handler
+	ask := !w.asking
+	w.asking = true
+	w.lock.Unlock()
+
+	logger.Error("Page is not responding", "window", w.window, "since", since.Round(time.Second), "outbox", w.outbox.depth())
+	if handler != nil {
+		handler(w.window, since)
+	}
+	if ask {
+		go w.ask()
+	}
+}
+
+func (w *watchdog) ask() {
+	reload := true
+	if w.attended {
+		reload = w.askUser()
+	}
+
+	w.lock.Lock()
+	w.asking = false
+	// it may have come back while the dialog was open
+	reload = reload && w.hung
+	w.lock.Unlock()
+	if reload {
+		logger.Info("Reloading the page", "window", w.window)
+		w.lock.Lock()
+		w.answered = time.Now()
+		w.hung = false
+		w.lock.Unlock()
+		w.reload()
+	}
+}
+
+func (w *watchdog) askUser() (reload bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Warn("Can't ask whether to reload", "error", err)
+			reload = true
+		}
+	}()
+	return dialog.Message("%s", "SAG Enhanced is not responding. Do you want to reload it?").Title("SAG Enhanced").YesNo()
+}
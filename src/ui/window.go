@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"sync"
+)
+
+// the webview UI can have more than one window (eg. a detached log viewer); they all load
+// pages of the realm and call the same bindings. where each window was is remembered in
+// windows.json in the data directory
+
+const MainWindow = "main"
+
+// call ids of the other windows are mapped to this range, so they can't clash with the
+// ids of the main window; navigations of the main window don't affect them
+const WindowCallIds = 1 << 30
+
+var ErrNoWindow = errors.New("no such window")
+
+// only the webview UI has native windows
+type Windows interface {
+	OpenWindow(name string, url string, options WindowOptions) error
+	CloseWindow(name string) error
+	ListWindows() []string
+	SetWindowTitle(name string, title string) error
+	SetWindowSize(name string, width int, height int) error
+	SetWindowAlwaysOnTop(name string, enabled bool) error
+	MinimizeWindow(name string) error
+	RestoreWindow(name string) error
+}
+
+type WindowOptions struct {
+	Title string
+	// only used the first time, afterwards the window gets the size it had last
+	Width  int
+	Height int
+}
+
+const (
+	defaultWidth  = 800
+	defaultHeight = 600
+	// bounds for sizes that are set or restored
+	MinWindowSize = 200
+	MaxWindowSize = 16384
+)
+
+type windowGeometry struct {
+	X         int  `json:"x"`
+	Y         int  `json:"y"`
+	Width     int  `json:"width"`
+	Height    int  `json:"height"`
+	Maximized bool `json:"maximized"`
+}
+
+func (g windowGeometry) valid() bool {
+	return g.Width >= MinWindowSize && g.Height >= MinWindowSize && g.Width <= MaxWindowSize && g.Height <= MaxWindowSize
+}
+
+var geometries = map[string]windowGeometry{}
+var geometryLock = sync.Mutex{}
+
+func geometryFile(dataDirectory string) string {
+	return path.Join(dataDirectory, "windows.json")
+}
+
+// a missing or broken file just means the windows start out at their default size
+func loadGeometries(dataDirectory string) {
+	content, err := os.ReadFile(geometryFile(dataDirectory))
+	if err != nil {
+		return
+	}
+	loaded := map[string]windowGeometry{}
+	if err := json.Unmarshal(content, &loaded); err != nil {
+		logger.Warn("Ignoring window state", "error", err)
+		return
+	}
+	geometryLock.Lock()
+	defer geometryLock.Unlock()
+	for name, geometry := range loaded {
+		if geometry.valid() {
+			geometries[name] = geometry
+		}
+	}
+}
+
+func saveGeometries(dataDirectory string) {
+	geometryLock.Lock()
+	content, err := json.MarshalIndent(geometries, "", "  ")
+	geometryLock.Unlock()
+	if err != nil {
+		return
+	}
+	filename := geometryFile(dataDirectory)
+	if err := os.WriteFile(filename+".tmp", content, 0644); err != nil {
+		logger.Warn("Failed to save window state", "error", err)
+		return
+	}
+	if err := os.Rename(filename+".tmp", filename); err != nil {
+		os.Remove(filename + ".tmp")
+		logger.Warn("Failed to save window state", "error", err)
+	}
+}
+
+func getGeometry(name string) (windowGeometry, bool) {
+	geometryLock.Lock()
+	defer geometryLock.Unlock()
+	geometry, ok := geometries[name]
+	return geometry, ok
+}
+
+func setGeometry(name string, geometry windowGeometry) {
+	geometryLock.Lock()
+	defer geometryLock.Unlock()
+	geometries[name] = geometry
+}
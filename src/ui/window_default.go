@@ -0,0 +1,32 @@
+//go:build !windows && !(linux && cgo)
+
+package ui
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// no native window controls on this platform (yet); windows always start out at their default size
+
+func readGeometry(window unsafe.Pointer) (geometry windowGeometry, minimized bool, ok bool) {
+	return windowGeometry{}, false, false
+}
+
+func applyGeometry(window unsafe.Pointer, geometry windowGeometry) {}
+
+func setAlwaysOnTop(window unsafe.Pointer, enabled bool) error {
+	return errors.ErrUnsupported
+}
+
+func minimizeWindow(window unsafe.Pointer) error {
+	return errors.ErrUnsupported
+}
+
+func restoreWindow(window unsafe.Pointer) error {
+	return errors.ErrUnsupported
+}
+
+func hideOnClose(window unsafe.Pointer) error {
+	return errors.ErrUnsupported
+}
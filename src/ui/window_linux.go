@@ -0,0 +1,93 @@
+//go:build linux && cgo
+
+package ui
+
+/*
+#cgo pkg-config: gtk+-3.0
+#include <gtk/gtk.h>
+
+static void window_geometry(void *window, int *x, int *y, int *width, int *height, int *maximized, int *minimized) {
+	gtk_window_get_position(GTK_WINDOW(window), x, y);
+	gtk_window_get_size(GTK_WINDOW(window), width, height);
+	GdkWindow *gdk = gtk_widget_get_window(GTK_WIDGET(window));
+	GdkWindowState state = gdk ? gdk_window_get_state(gdk) : 0;
+	*maximized = (state & GDK_WINDOW_STATE_MAXIMIZED) != 0;
+	*minimized = (state & GDK_WINDOW_STATE_ICONIFIED) != 0 || !gtk_widget_get_visible(GTK_WIDGET(window));
+}
+
+static void window_move(void *window, int x, int y) {
+	gtk_window_move(GTK_WINDOW(window), x, y);
+}
+
+static void window_maximize(void *window) {
+	gtk_window_maximize(GTK_WINDOW(window));
+}
+
+static void window_keep_above(void *window, int enabled) {
+	gtk_window_set_keep_above(GTK_WINDOW(window), enabled);
+}
+
+static void window_iconify(void *window) {
+	gtk_window_iconify(GTK_WINDOW(window));
+}
+
+static void window_restore(void *window) {
+	gtk_window_deiconify(GTK_WINDOW(window));
+	gtk_window_present(GTK_WINDOW(window));
+}
+
+static gboolean window_hide(GtkWidget *window, GdkEvent *event, gpointer data) {
+	gtk_widget_hide(window);
+	return TRUE;
+}
+
+static void window_hide_on_close(void *window) {
+	g_signal_connect(G_OBJECT(window), "delete-event", G_CALLBACK(window_hide), NULL);
+}
+*/
+import "C"
+import "unsafe"
+
+// all of these take the GtkWindow of the webview and must be called on the UI thread.
+// wayland doesn't tell windows where they are, so there only the size is remembered
+
+// while maximized, the size is the one of the screen; hidden counts as minimized
+func readGeometry(window unsafe.Pointer) (geometry windowGeometry, minimized bool, ok bool) {
+	var x, y, width, height, maximized, iconified C.int
+	C.window_geometry(window, &x, &y, &width, &height, &maximized, &iconified)
+	geometry = windowGeometry{X: int(x), Y: int(y), Width: int(width), Height: int(height), Maximized: maximized != 0}
+	return geometry, iconified != 0, true
+}
+
+// the size is set through the webview
+func applyGeometry(window unsafe.Pointer, geometry windowGeometry) {
+	C.window_move(window, C.int(geometry.X), C.int(geometry.Y))
+	if geometry.Maximized {
+		C.window_maximize(window)
+	}
+}
+
+func setAlwaysOnTop(window unsafe.Pointer, enabled bool) error {
+	value := C.int(0)
+	if enabled {
+		value = 1
+	}
+	C.window_keep_above(window, value)
+	return nil
+}
+
+func minimizeWindow(window unsafe.Pointer) error {
+	C.window_iconify(window)
+	return nil
+}
+
+func restoreWindow(window unsafe.Pointer) error {
+	C.window_restore(window)
+	return nil
+}
+
+// closing the window only hides it; presenting it (focusWindow) brings it back
+func hideOnClose(window unsafe.Pointer) error {
+	C.window_hide_on_close(window)
+	return nil
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	procGetWindowPlacement = user32.NewProc("GetWindowPlacement")
+	procSetWindowPlacement = user32.NewProc("SetWindowPlacement")
+	procSetWindowPos       = user32.NewProc("SetWindowPos")
+	procMonitorFromRect    = user32.NewProc("MonitorFromRect")
+	procIsWindowVisible    = user32.NewProc("IsWindowVisible")
+	procSetWindowLongPtr   = user32.NewProc("SetWindowLongPtrW")
+	procCallWindowProc     = user32.NewProc("CallWindowProcW")
+)
+
+const (
+	swShowNormal       = 1
+	swShowMinimized    = 2
+	swShowMaximized    = 3
+	swMinimize         = 6
+	swHide             = 0
+	gwlpWndProc        = ^uintptr(3) // -4
+	wmClose            = 0x0010
+	hwndTopmost        = ^uintptr(0) // -1
+	hwndNoTopmost      = ^uintptr(1) // -2
+	swpNoSize          = 0x0001
+	swpNoMove          = 0x0002
+	swpNoActivate      = 0x0010
+	monitorDefaultNull = 0
+)
+
+type rect struct {
+	left, top, right, bottom int32
+}
+
+type windowPlacement struct {
+	length         uint32
+	flags          uint32
+	showCmd        uint32
+	minPosition    [2]int32
+	maxPosition    [2]int32
+	normalPosition rect
+}
+
+// all of these take the HWND of the webview and must be called on the UI thread.
+// the placement has the size the window has when it is not maximized
+
+func readGeometry(window unsafe.Pointer) (geometry windowGeometry, minimized bool, ok bool) {
+	placement := windowPlacement{}
+	placement.length = uint32(unsafe.Sizeof(placement))
+	if result, _, _ := procGetWindowPlacement.Call(uintptr(window), uintptr(unsafe.Pointer(&placement))); result == 0 {
+		return windowGeometry{}, false, false
+	}
+	normal := placement.normalPosition
+	geometry = windowGeometry{
+		X:         int(normal.left),
+		Y:         int(normal.top),
+		Width:     int(normal.right - normal.left),
+		Height:    int(normal.bottom - normal.top),
+		Maximized: placement.showCmd == swShowMaximized,
+	}
+	visible, _, _ := procIsWindowVisible.Call(uintptr(window))
+	return geometry, placement.showCmd == swShowMinimized || visible == 0, true
+}
+
+func applyGeometry(window unsafe.Pointer, geometry windowGeometry) {
+	normal := rect{
+		left:   int32(geometry.X),
+		top:    int32(geometry.Y),
+		right:  int32(geometry.X + geometry.Width),
+		bottom: int32(geometry.Y + geometry.Height),
+	}
+	// the screen it was on may not be there anymore
+	if monitor, _, _ := procMonitorFromRect.Call(uintptr(unsafe.Pointer(&normal)), monitorDefaultNull); monitor == 0 {
+		return
+	}
+	placement := windowPlacement{showCmd: swShowNormal, normalPosition: normal}
+	placement.length = uint32(unsafe.Sizeof(placement))
+	if geometry.Maximized {
+		placement.showCmd = swShowMaximized
+	}
+	procSetWindowPlacement.Call(uintptr(window), uintptr(unsafe.Pointer(&placement)))
+}
+
+func setAlwaysOnTop(window unsafe.Pointer, enabled bool) error {
+	after := hwndNoTopmost
+	if enabled {
+		after = hwndTopmost
+	}
+	procSetWindowPos.Call(uintptr(window), after, 0, 0, 0, 0, swpNoMove|swpNoSize|swpNoActivate)
+	return nil
+}
+
+func minimizeWindow(window unsafe.Pointer) error {
+	procShowWindow.Call(uintptr(window), swMinimize)
+	return nil
+}
+
+func restoreWindow(window unsafe.Pointer) error {
+	focusWindow(window)
+	return nil
+}
+
+// the window procedure of the webview, which gets everything but the close
+var webviewWndProc uintptr
+
+// callbacks can't be freed, so there is only ever one
+var hideWndProc = windows.NewCallback(func(hwnd uintptr, msg uint32, wparam uintptr, lparam uintptr) uintptr {
+	if msg == wmClose {
+		procShowWindow.Call(hwnd, swHide)
+		return 0
+	}
+	result, _, _ := procCallWindowProc.Call(webviewWndProc, hwnd, uintptr(msg), wparam, lparam)
+	return result
+})
+
+// closing the window only hides it; focusWindow brings it back
+func hideOnClose(window unsafe.Pointer) error {
+	previous, _, err := procSetWindowLongPtr.Call(uintptr(window), gwlpWndProc, hideWndProc)
+	if previous == 0 {
+		return err
+	}
+	webviewWndProc = previous
+	return nil
+}